@@ -0,0 +1,93 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet"
+	"github.com/palantir/okgo/checker"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// NewDoctorCmd returns a command that diagnoses the environment in which the asset runs "go vet" and prints whether
+// every diagnostic check passed along with hints for fixing the problems that were found. The command fails if any
+// check fails.
+func NewDoctorCmd(govetCreator checker.Creator) *cobra.Command {
+	var (
+		configYMLFlagVal string
+		formatFlagVal    string
+	)
+	doctorCmd := &cobra.Command{
+		Use:   "doctor",
+		Short: "Diagnose the environment in which go vet is run",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			checker, err := checkerFromConfig(govetCreator, configYMLFlagVal)
+			if err != nil {
+				return err
+			}
+			checks := checker.Doctor()
+			switch formatFlagVal {
+			case formatText:
+				err = printDoctorChecksText(cmd.OutOrStdout(), checks)
+			case formatJSON:
+				err = printJSON(cmd.OutOrStdout(), checks)
+			default:
+				err = errors.Errorf("unsupported format %q: must be %q or %q", formatFlagVal, formatText, formatJSON)
+			}
+			if err != nil {
+				return err
+			}
+			failed := 0
+			for _, check := range checks {
+				if check.Status == govet.DoctorFail {
+					failed++
+				}
+			}
+			if failed > 0 {
+				return errors.Errorf("%d of %d checks failed", failed, len(checks))
+			}
+			return nil
+		},
+	}
+	doctorCmd.Flags().StringVar(&configYMLFlagVal, configYMLFlagName, "", "YML of Checker configuration")
+	doctorCmd.Flags().StringVar(&formatFlagVal, formatFlagName, formatText, fmt.Sprintf("output format (%q or %q)", formatText, formatJSON))
+	return doctorCmd
+}
+
+func printDoctorChecksText(w io.Writer, checks []govet.DoctorCheck) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "STATUS\tCHECK\tDETAIL")
+	for _, check := range checks {
+		// details that span multiple lines, such as the output of the go command, continue in the detail column
+		lines := strings.Split(check.Detail, "\n")
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", strings.ToUpper(string(check.Status)), check.Name, lines[0])
+		for _, line := range lines[1:] {
+			_, _ = fmt.Fprintf(tw, "\t\t%s\n", strings.TrimSpace(line))
+		}
+		if check.Hint != "" {
+			_, _ = fmt.Fprintf(tw, "\t\thint: %s\n", check.Hint)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return errors.Wrapf(err, "failed to write report")
+	}
+	return nil
+}
@@ -0,0 +1,81 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+// NewExplainCmd returns a command that prints the documentation, flags and an example of the analyzer with the
+// provided name.
+func NewExplainCmd() *cobra.Command {
+	var formatFlagVal string
+	explainCmd := &cobra.Command{
+		Use:   "explain <analyzer>",
+		Short: "Print the documentation, flags and an example of an analyzer",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			explanation, err := govet.ExplainAnalyzer(args[0])
+			if err != nil {
+				return err
+			}
+			switch formatFlagVal {
+			case formatText:
+				printExplanationText(cmd.OutOrStdout(), explanation)
+				return nil
+			case formatJSON:
+				return printJSON(cmd.OutOrStdout(), explanation)
+			default:
+				return errors.Errorf("unsupported format %q: must be %q or %q", formatFlagVal, formatText, formatJSON)
+			}
+		},
+	}
+	explainCmd.Flags().StringVar(&formatFlagVal, formatFlagName, formatText, fmt.Sprintf("output format (%q or %q)", formatText, formatJSON))
+	return explainCmd
+}
+
+func printExplanationText(w io.Writer, explanation govet.AnalyzerExplanation) {
+	_, _ = fmt.Fprintln(w, strings.TrimSpace(explanation.Doc))
+	if len(explanation.Flags) > 0 {
+		_, _ = fmt.Fprintln(w, "\nFlags:")
+		for _, f := range explanation.Flags {
+			_, _ = fmt.Fprintf(w, "  -%s (default %q)\n    \t%s\n", f.Name, f.DefaultValue, f.Usage)
+		}
+	}
+	if explanation.Example != nil {
+		_, _ = fmt.Fprintf(w, "\nBad:\n%s\n", indent(explanation.Example.Bad))
+		_, _ = fmt.Fprintf(w, "\nGood:\n%s\n", indent(explanation.Example.Good))
+	}
+	if explanation.URL != "" {
+		_, _ = fmt.Fprintf(w, "\nFull documentation: %s\n", explanation.URL)
+	}
+}
+
+// indent indents every non-empty line of the provided text with four spaces.
+func indent(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "    " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
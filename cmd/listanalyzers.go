@@ -0,0 +1,118 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package cmd provides the commands that the asset provides in addition to the standard okgo asset commands. The
+// commands that use the checker configuration create checkers using the creator of the asset, so that they reflect the
+// analyzers and default configuration of assets that are built using creator options.
+package cmd
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"text/tabwriter"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet"
+	"github.com/palantir/okgo/checker"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	configYMLFlagName = "config-yml"
+	formatFlagName    = "format"
+
+	formatText = "text"
+	formatJSON = "json"
+)
+
+// NewListAnalyzersCmd returns a command that prints every analyzer that is available to the checker along with
+// whether it is enabled by the provided configuration, its summary and its flags. If packages are provided, the
+// adjustments that are made for the Go versions of their modules are printed as well.
+func NewListAnalyzersCmd(govetCreator checker.Creator) *cobra.Command {
+	var (
+		configYMLFlagVal string
+		formatFlagVal    string
+	)
+	listAnalyzersCmd := &cobra.Command{
+		Use:   "list-analyzers [packages]",
+		Short: "Print the analyzers that are available and whether they are enabled by the provided configuration",
+		Args:  cobra.ArbitraryArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			checker, err := checkerFromConfig(govetCreator, configYMLFlagVal)
+			if err != nil {
+				return err
+			}
+			infos, err := checker.Analyzers(args...)
+			if err != nil {
+				return err
+			}
+			switch formatFlagVal {
+			case formatText:
+				return printAnalyzersText(cmd.OutOrStdout(), infos)
+			case formatJSON:
+				return printJSON(cmd.OutOrStdout(), infos)
+			default:
+				return errors.Errorf("unsupported format %q: must be %q or %q", formatFlagVal, formatText, formatJSON)
+			}
+		},
+	}
+	listAnalyzersCmd.Flags().StringVar(&configYMLFlagVal, configYMLFlagName, "", "YML of Checker configuration")
+	listAnalyzersCmd.Flags().StringVar(&formatFlagVal, formatFlagName, formatText, fmt.Sprintf("output format (%q or %q)", formatText, formatJSON))
+	return listAnalyzersCmd
+}
+
+// checkerFromConfig returns the checker that the provided creator creates for the provided configuration YML.
+func checkerFromConfig(govetCreator checker.Creator, configYML string) (*govet.Checker, error) {
+	checker, err := govetCreator.Creator()([]byte(configYML))
+	if err != nil {
+		return nil, err
+	}
+	govetChecker, ok := checker.(*govet.Checker)
+	if !ok {
+		return nil, errors.Errorf("unexpected checker type %T", checker)
+	}
+	return govetChecker, nil
+}
+
+func printAnalyzersText(w io.Writer, infos []govet.AnalyzerInfo) error {
+	tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "NAME\tSTATE\tSUMMARY")
+	for _, info := range infos {
+		state := "disabled"
+		if info.Enabled {
+			state = "enabled"
+		}
+		_, _ = fmt.Fprintf(tw, "%s\t%s\t%s\n", info.Name, state, info.Summary)
+		for _, f := range info.Flags {
+			_, _ = fmt.Fprintf(tw, "\t\t  -%s (default %q): %s\n", f.Name, f.DefaultValue, f.Usage)
+		}
+		for _, adjustment := range info.GoVersionAdjustments {
+			_, _ = fmt.Fprintf(tw, "\t\t  %s\n", adjustment)
+		}
+	}
+	if err := tw.Flush(); err != nil {
+		return errors.Wrapf(err, "failed to write analyzers")
+	}
+	return nil
+}
+
+func printJSON(w io.Writer, v any) error {
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return errors.Wrapf(err, "failed to write output as JSON")
+	}
+	return nil
+}
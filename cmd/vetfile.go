@@ -0,0 +1,106 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet"
+	"github.com/palantir/okgo/checker"
+	"github.com/pkg/errors"
+	"github.com/spf13/cobra"
+)
+
+const (
+	stdinFlagName   = "stdin"
+	overlayFlagName = "overlay"
+)
+
+// NewVetFileCmd returns a command that vets the package that contains the provided file and prints the issues for that
+// file. The contents of the file can be provided on stdin and the contents of other files can be replaced using an
+// overlay file in the format accepted by the "-overlay" build flag, which allows unsaved editor buffers to be vetted.
+func NewVetFileCmd(govetCreator checker.Creator) *cobra.Command {
+	var (
+		configYMLFlagVal string
+		stdinFlagVal     bool
+		overlayFlagVal   string
+	)
+	vetFileCmd := &cobra.Command{
+		Use:   "vet-file <file>",
+		Short: "Vet the package that contains the provided file and print the issues for that file",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			checker, err := checkerFromConfig(govetCreator, configYMLFlagVal)
+			if err != nil {
+				return err
+			}
+			filename, err := filepath.Abs(args[0])
+			if err != nil {
+				return errors.Wrapf(err, "failed to determine absolute path of %s", args[0])
+			}
+			overlay := govet.Overlay{
+				Replace: make(map[string]string),
+			}
+			if overlayFlagVal != "" {
+				overlayBytes, err := os.ReadFile(overlayFlagVal)
+				if err != nil {
+					return errors.Wrapf(err, "failed to read overlay file")
+				}
+				if err := json.Unmarshal(overlayBytes, &overlay); err != nil {
+					return errors.Wrapf(err, "failed to unmarshal overlay file %s", overlayFlagVal)
+				}
+				if overlay.Replace == nil {
+					overlay.Replace = make(map[string]string)
+				}
+			}
+			if stdinFlagVal {
+				contentFile, err := writeTempContent(cmd.InOrStdin(), filepath.Ext(filename))
+				if err != nil {
+					return err
+				}
+				defer func() {
+					_ = os.Remove(contentFile)
+				}()
+				overlay.Replace[filename] = contentFile
+			}
+			checker.CheckFile(filename, overlay, cmd.OutOrStdout())
+			return nil
+		},
+	}
+	vetFileCmd.Flags().StringVar(&configYMLFlagVal, configYMLFlagName, "", "YML of Checker configuration")
+	vetFileCmd.Flags().BoolVar(&stdinFlagVal, stdinFlagName, false, "read the contents of the file from stdin")
+	vetFileCmd.Flags().StringVar(&overlayFlagVal, overlayFlagName, "", "JSON file in the format accepted by the -overlay build flag that replaces the contents of files")
+	return vetFileCmd
+}
+
+// writeTempContent writes the content read from r to a temporary file with the provided extension and returns its path.
+func writeTempContent(r io.Reader, ext string) (rPath string, rErr error) {
+	f, err := os.CreateTemp("", "govet-stdin-*"+ext)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to create file for stdin content")
+	}
+	defer func() {
+		if err := f.Close(); err != nil && rErr == nil {
+			rErr = errors.Wrapf(err, "failed to close file for stdin content")
+		}
+	}()
+	if _, err := io.Copy(f, r); err != nil {
+		return "", errors.Wrapf(err, "failed to read content from stdin")
+	}
+	return f.Name(), nil
+}
@@ -0,0 +1,101 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/cmd"
+	"github.com/palantir/godel-okgo-asset-govet/govet"
+	"github.com/palantir/okgo/checker"
+	"github.com/palantir/okgo/okgo"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestVetFileStdin(t *testing.T) {
+	t.Setenv("TMPDIR", t.TempDir())
+	wd := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(wd, "go.mod"), []byte("module foo\n"), 0644))
+	require.NoError(t, os.Mkdir(filepath.Join(wd, "bar"), 0755))
+	filename := filepath.Join(wd, "bar", "bar.go")
+	require.NoError(t, os.WriteFile(filename, []byte("package bar\n"), 0644))
+	const content = "package bar\n\nimport \"fmt\"\n\nfunc bar() {\n\tfmt.Printf(\"%s\")\n}\n"
+
+	runner := &overlayRunner{replaced: filename}
+	govetCreator := checker.NewCreator(govet.TypeName, govet.Priority, func(cfgYML []byte) (okgo.Checker, error) {
+		return &govet.Checker{
+			VetTool: "/path/govet-asset",
+			Runner:  runner,
+			Getwd: func() (string, error) {
+				return wd, nil
+			},
+		}, nil
+	})
+	vetFileCmd := cmd.NewVetFileCmd(govetCreator)
+	vetFileCmd.SetArgs([]string{"--stdin", filename})
+	vetFileCmd.SetIn(strings.NewReader(content))
+	buf := &bytes.Buffer{}
+	vetFileCmd.SetOut(buf)
+	require.NoError(t, vetFileCmd.Execute())
+
+	// the contents of the file are replaced by the content read from stdin, and positions in the replacement are
+	// mapped back to the file
+	assert.Equal(t, content, runner.content)
+	assert.Equal(t, `{"path":"bar/bar.go","line":6,"col":2,"content":"fmt.Printf format %s reads arg #1, but call has 0 args"}
+`, buf.String())
+}
+
+// overlayRunner is a govet.Runner that records the content of the overlay replacement of a file and reports a finding
+// in the replacement.
+type overlayRunner struct {
+	replaced string
+	content  string
+}
+
+func (r *overlayRunner) Run(ctx context.Context, cmd govet.Command, stdout, stderr io.Writer) (int, error) {
+	var overlay govet.Overlay
+	for _, arg := range cmd.Args {
+		if overlayFile, ok := strings.CutPrefix(arg, "-overlay="); ok {
+			overlayBytes, err := os.ReadFile(overlayFile)
+			if err != nil {
+				return -1, err
+			}
+			if err := json.Unmarshal(overlayBytes, &overlay); err != nil {
+				return -1, err
+			}
+		}
+	}
+	replacement, ok := overlay.Replace[r.replaced]
+	if !ok {
+		return -1, errors.Errorf("overlay does not replace %s", r.replaced)
+	}
+	contentBytes, err := os.ReadFile(replacement)
+	if err != nil {
+		return -1, err
+	}
+	r.content = string(contentBytes)
+	_, err = fmt.Fprintf(stdout, `{"foo/bar": {"printf": [{"posn": %q, "message": "fmt.Printf format %%s reads arg #1, but call has 0 args"}]}}`+"\n", replacement+":6:2")
+	return 0, err
+}
@@ -0,0 +1,55 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package cmd
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"time"
+
+	"github.com/palantir/okgo/checker"
+	"github.com/spf13/cobra"
+)
+
+const intervalFlagName = "interval"
+
+// NewWatchCmd returns a command that vets the provided packages and re-vets the affected packages whenever their files
+// change, printing the findings that were added and resolved by every change.
+func NewWatchCmd(govetCreator checker.Creator) *cobra.Command {
+	var (
+		configYMLFlagVal string
+		intervalFlagVal  time.Duration
+	)
+	watchCmd := &cobra.Command{
+		Use:   "watch [packages]",
+		Short: "Vet the provided packages and re-vet the affected packages whenever files change",
+		RunE: func(cmd *cobra.Command, args []string) error {
+			checker, err := checkerFromConfig(govetCreator, configYMLFlagVal)
+			if err != nil {
+				return err
+			}
+			if len(args) == 0 {
+				args = []string{"./..."}
+			}
+			ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+			defer stop()
+			return checker.Watch(ctx, args, intervalFlagVal, cmd.OutOrStdout())
+		},
+	}
+	watchCmd.Flags().StringVar(&configYMLFlagVal, configYMLFlagName, "", "YML of Checker configuration")
+	watchCmd.Flags().DurationVar(&intervalFlagVal, intervalFlagName, 500*time.Millisecond, "interval at which files are polled for changes")
+	return watchCmd
+}
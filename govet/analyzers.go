@@ -0,0 +1,183 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet
+
+import (
+	"context"
+	"flag"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/analyzers"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/pkgpaths"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+)
+
+// AnalyzerInfo describes an analyzer that the checker can run.
+type AnalyzerInfo struct {
+	Name string `json:"name"`
+	// Enabled is true if the analyzer is run by the checker.
+	Enabled bool `json:"enabled"`
+	// Default is true if the analyzer is run unless it is disabled. Analyzers in the "go vet" suite and registered
+	// analyzers are enabled by default, while the extras that are bundled with the asset and registered optional
+	// analyzers must be enabled explicitly.
+	Default bool `json:"default"`
+	// Summary is the first paragraph of the documentation of the analyzer.
+	Summary string `json:"summary"`
+	// URL is the URL of the full documentation of the analyzer. May be empty.
+	URL   string         `json:"url,omitempty"`
+	Flags []AnalyzerFlag `json:"flags,omitempty"`
+	// GoVersionAdjustments are the adjustments to whether the analyzer is run that are made for the modules of the
+	// packages for which the analyzers were requested because of their Go versions.
+	GoVersionAdjustments []GoVersionAdjustment `json:"goVersionAdjustments,omitempty"`
+}
+
+// AnalyzerFlag describes a flag of an analyzer. Flags are provided to "go vet" in the form "-<analyzer>.<flag>".
+type AnalyzerFlag struct {
+	Name         string `json:"name"`
+	Usage        string `json:"usage"`
+	DefaultValue string `json:"defaultValue"`
+}
+
+// RegisterAnalyzers makes the provided analyzers available to the checker in addition to the bundled analyzers and
+// enables them by default. It allows building assets that run custom analyzers, and must be called in every process
+// of such an asset before VetToolMain is called or checkers are created, since "go vet" runs the asset itself as its
+// vet tool. Registering an analyzer that is already registered has no effect. Returns an error if an analyzer is not
+// valid or if its name is the name of another available analyzer.
+func RegisterAnalyzers(analyzerList ...*analysis.Analyzer) error {
+	return analyzers.Register(analyzerList, false)
+}
+
+// RegisterOptionalAnalyzers is like RegisterAnalyzers, but the registered analyzers are disabled by default, like the
+// bundled extras.
+func RegisterOptionalAnalyzers(analyzerList ...*analysis.Analyzer) error {
+	return analyzers.Register(analyzerList, true)
+}
+
+// ValidateAnalyzers returns an error if the provided lists of analyzers to enable and disable name an analyzer that
+// is not available or name the same analyzer.
+func ValidateAnalyzers(enable, disable []string) error {
+	return analyzers.Validate(analyzers.All(), enable, disable)
+}
+
+// ValidatePrintfFuncs returns an error if a provided name of a function that the printf analyzer checks is empty or
+// contains commas or whitespace.
+func ValidatePrintfFuncs(names []string) error {
+	return analyzers.ValidatePrintfFuncs(names)
+}
+
+// Analyzers returns information about every analyzer that is available to the checker, including whether it is
+// enabled by the checker's configuration. The analyzers in the "go vet" suite are returned first, followed by the
+// bundled extras and the registered analyzers. If packages are provided and the checker matches analyzers to Go
+// versions, the information includes the adjustments that are made for the modules of the packages. Relative package
+// paths are interpreted relative to the working directory.
+func (c *Checker) Analyzers(pkgPaths ...string) ([]AnalyzerInfo, error) {
+	enabled, err := analyzers.Select(analyzers.All(), c.EnableAnalyzers, c.DisableAnalyzers)
+	if err != nil {
+		return nil, err
+	}
+	var adjustments []GoVersionAdjustment
+	if len(pkgPaths) > 0 {
+		wd, err := c.getwd()
+		if err != nil {
+			return nil, err
+		}
+		opts := c.options(nil, wd, wd)
+		resolved, errs := pkgpaths.Resolve(pkgPaths, wd, opts.getenv)
+		if len(errs) > 0 {
+			return nil, errs[0]
+		}
+		opts.Packages = resolved
+		if _, adjustments, err = opts.goVersionAnalyzers(context.Background(), opts.runner(), resolved, enabled); err != nil {
+			return nil, err
+		}
+	}
+	var infos []AnalyzerInfo
+	for _, analyzer := range analyzers.All() {
+		info := AnalyzerInfo{
+			Name:    analyzer.Name,
+			Default: analyzers.IsDefault(analyzer),
+			Summary: analyzers.Title(analyzer),
+			URL:     analyzer.URL,
+		}
+		for _, enabledAnalyzer := range enabled {
+			if enabledAnalyzer == analyzer {
+				info.Enabled = true
+				break
+			}
+		}
+		info.Flags = analyzerFlags(analyzer)
+		for _, adjustment := range adjustments {
+			if adjustment.Analyzer == analyzer.Name {
+				info.GoVersionAdjustments = append(info.GoVersionAdjustments, adjustment)
+			}
+		}
+		infos = append(infos, info)
+	}
+	return infos, nil
+}
+
+// AnalyzerExplanation is the documentation of an analyzer.
+type AnalyzerExplanation struct {
+	Name string `json:"name"`
+	// Doc is the full documentation of the analyzer.
+	Doc   string         `json:"doc"`
+	URL   string         `json:"url,omitempty"`
+	Flags []AnalyzerFlag `json:"flags,omitempty"`
+	// Example is an example of code that the analyzer reports along with code that fixes the finding. Nil if there is
+	// no example for the analyzer.
+	Example *AnalyzerExample `json:"example,omitempty"`
+}
+
+// AnalyzerExample is an example of code that an analyzer reports along with code that fixes the finding.
+type AnalyzerExample struct {
+	Bad  string `json:"bad"`
+	Good string `json:"good"`
+}
+
+// ExplainAnalyzer returns the documentation of the available analyzer with the provided name. The documentation is
+// embedded in the asset, so it is available offline.
+func ExplainAnalyzer(name string) (AnalyzerExplanation, error) {
+	for _, analyzer := range analyzers.All() {
+		if analyzer.Name != name {
+			continue
+		}
+		explanation := AnalyzerExplanation{
+			Name:  analyzer.Name,
+			Doc:   analyzer.Doc,
+			URL:   analyzer.URL,
+			Flags: analyzerFlags(analyzer),
+		}
+		if example, ok := analyzers.ExampleFor(analyzer.Name); ok {
+			explanation.Example = &AnalyzerExample{
+				Bad:  example.Bad,
+				Good: example.Good,
+			}
+		}
+		return explanation, nil
+	}
+	return AnalyzerExplanation{}, errors.Errorf("unknown analyzer %q", name)
+}
+
+func analyzerFlags(analyzer *analysis.Analyzer) []AnalyzerFlag {
+	var flags []AnalyzerFlag
+	analyzer.Flags.VisitAll(func(f *flag.Flag) {
+		flags = append(flags, AnalyzerFlag{
+			Name:         analyzer.Name + "." + f.Name,
+			Usage:        f.Usage,
+			DefaultValue: f.DefValue,
+		})
+	})
+	return flags
+}
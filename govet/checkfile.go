@@ -0,0 +1,136 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+
+	"github.com/pkg/errors"
+)
+
+// overlayDirPattern is the pattern of the names of the temporary directories that hold the overlay and the copies of
+// its replacements for a single run.
+const overlayDirPattern = "govet-overlay-*"
+
+// Overlay is the content of the file provided to the "-overlay" build flag. Replace maps the paths of files to the
+// paths of the files that replace their contents. An empty replacement path causes the file to be treated as if it
+// did not exist. Relative paths are resolved against the working directory.
+type Overlay struct {
+	Replace map[string]string `json:"Replace"`
+}
+
+// CheckFile runs the checker on the package that contains the provided file and writes the issues for that file to
+// stdout in the same format as Check. The provided overlay replaces the contents of files before they are vetted,
+// which allows unsaved editor buffers to be vetted. Reports and annotations are not written.
+//
+// The overlay and copies of its replacements are written to a new temporary directory that is only accessible by the
+// current user and is removed after the run.
+func (c *Checker) CheckFile(filename string, overlay Overlay, stdout io.Writer) {
+	w := &issueWriter{
+		stdout: stdout,
+	}
+	wd, err := c.getwd()
+	if err != nil {
+		w.writeError(err)
+		return
+	}
+	if w.debug, err = debugEnabled(c.Debug); err != nil {
+		w.writeError(err)
+		return
+	}
+	absFilename := resolvePath(filename, wd)
+	replacements := make(map[string]string)
+	var flags []string
+	if len(overlay.Replace) > 0 {
+		dir, err := os.MkdirTemp("", overlayDirPattern)
+		if err != nil {
+			w.writeError(errors.Wrapf(err, "failed to create directory for overlay"))
+			return
+		}
+		defer func() {
+			_ = os.RemoveAll(dir)
+		}()
+		absOverlay := Overlay{
+			Replace: make(map[string]string),
+		}
+		for path, replacement := range overlay.Replace {
+			absPath := resolvePath(path, wd)
+			if replacement != "" {
+				name, err := copyReplacement(resolvePath(replacement, wd), absPath, dir)
+				if err != nil {
+					w.writeError(err)
+					return
+				}
+				replacement = filepath.Join(dir, name)
+				replacements[name] = absPath
+			}
+			absOverlay.Replace[absPath] = replacement
+		}
+		overlayFile, err := writeOverlay(absOverlay, dir)
+		if err != nil {
+			w.writeError(err)
+			return
+		}
+		flags = append(flags, "-overlay="+overlayFile)
+	}
+	opts := c.options([]string{filepath.Dir(absFilename)}, wd, wd)
+	if _, _, err := opts.vet(context.Background(), w, func(run *vetRun) {
+		run.replacements = replacements
+		run.onlyFile = absFilename
+		run.flags = append(run.flags, flags...)
+	}); err != nil {
+		w.writeError(err)
+	}
+}
+
+// copyReplacement copies the provided replacement for the file at the provided absolute path to the provided overlay
+// directory and returns the path of the copy relative to that directory. The relative path only depends on the path of
+// the replaced file: "go vet" caches its output based on the contents of the replacement rather than its path, so
+// output that is replayed from the cache refers to the copy in the overlay directory of the run that cached it, which
+// can then still be mapped back to the replaced file.
+func copyReplacement(replacement, replaced, dir string) (string, error) {
+	content, err := os.ReadFile(replacement)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to read overlay replacement for %s", replaced)
+	}
+	hash := sha256.Sum256([]byte(replaced))
+	name := filepath.Join(hex.EncodeToString(hash[:8]), filepath.Base(replaced))
+	if err := os.Mkdir(filepath.Join(dir, filepath.Dir(name)), 0700); err != nil {
+		return "", errors.Wrapf(err, "failed to create directory for overlay replacement")
+	}
+	if err := os.WriteFile(filepath.Join(dir, name), content, 0600); err != nil {
+		return "", errors.Wrapf(err, "failed to write overlay replacement")
+	}
+	return name, nil
+}
+
+// writeOverlay writes the provided overlay to a file in the provided directory and returns its path.
+func writeOverlay(overlay Overlay, dir string) (string, error) {
+	overlayBytes, err := json.Marshal(overlay)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to marshal overlay as JSON")
+	}
+	overlayFile := filepath.Join(dir, "overlay.json")
+	if err := os.WriteFile(overlayFile, overlayBytes, 0600); err != nil {
+		return "", errors.Wrapf(err, "failed to write overlay file")
+	}
+	return overlayFile, nil
+}
@@ -0,0 +1,146 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckFile(t *testing.T) {
+	for i, tc := range []struct {
+		name     string
+		filename string
+		// overlay maps paths relative to the working directory to the contents of the files that replace them
+		overlay map[string]string
+		// cached is true if "go vet" reports the positions in the replacement in the overlay directory of an earlier
+		// run, as it does when it replays its output from the cache
+		cached bool
+		want   string
+	}{
+		{
+			name:     "only issues for the provided file are written",
+			filename: "bar/bar.go",
+			want: `{"path":"bar/bar.go","line":5,"col":2,"content":"fmt.Printf format %s reads arg #1, but call has 0 args"}
+`,
+		},
+		{
+			name:     "only issues for the provided absolute file are written",
+			filename: "$WD/bar/other.go",
+			want: `{"path":"bar/other.go","line":7,"col":2,"content":"result of fmt.Sprint call not used"}
+`,
+		},
+		{
+			name:     "positions in overlay replacements are mapped to the replaced file",
+			filename: "bar/bar.go",
+			overlay:  map[string]string{"bar/bar.go": "package bar\n"},
+			want: `{"path":"bar/bar.go","line":5,"col":2,"content":"fmt.Printf format %s reads arg #1, but call has 0 args"}
+`,
+		},
+		{
+			name:     "positions in overlay replacements of earlier runs are mapped to the replaced file",
+			filename: "bar/bar.go",
+			overlay:  map[string]string{"bar/bar.go": "package bar\n"},
+			cached:   true,
+			want: `{"path":"bar/bar.go","line":5,"col":2,"content":"fmt.Printf format %s reads arg #1, but call has 0 args"}
+`,
+		},
+	} {
+		t.Setenv("TMPDIR", t.TempDir())
+		wd := newModule(t)
+		require.NoError(t, os.Mkdir(filepath.Join(wd, "bar"), 0755), "Case %d: %s", i, tc.name)
+		for _, name := range []string{"bar.go", "other.go"} {
+			require.NoError(t, os.WriteFile(filepath.Join(wd, "bar", name), []byte("package bar\n"), 0644), "Case %d: %s", i, tc.name)
+		}
+		overlay := govet.Overlay{
+			Replace: make(map[string]string),
+		}
+		for path, content := range tc.overlay {
+			replacement := filepath.Join(t.TempDir(), filepath.Base(path))
+			require.NoError(t, os.WriteFile(replacement, []byte(content), 0644), "Case %d: %s", i, tc.name)
+			overlay.Replace[path] = replacement
+		}
+
+		runner := &fakeRunner{wd: wd}
+		runner.loadFixture(t, "single-file")
+		var gotOverlay govet.Overlay
+		// gotContents maps the replaced paths to the contents of their replacements while "go vet" runs
+		gotContents := make(map[string]string)
+		runner.onRun = func(cmd govet.Command) {
+			file := filepath.Join(wd, "bar", "bar.go")
+			for _, arg := range cmd.Args {
+				if overlayFile, ok := strings.CutPrefix(arg, "-overlay="); ok {
+					overlayBytes, err := os.ReadFile(overlayFile)
+					require.NoError(t, err, "Case %d: %s", i, tc.name)
+					require.NoError(t, json.Unmarshal(overlayBytes, &gotOverlay), "Case %d: %s", i, tc.name)
+					for path, replacement := range gotOverlay.Replace {
+						content, err := os.ReadFile(replacement)
+						require.NoError(t, err, "Case %d: %s", i, tc.name)
+						gotContents[path] = string(content)
+					}
+					if replacement, ok := gotOverlay.Replace[file]; ok {
+						file = replacement
+						if tc.cached {
+							file = filepath.Join(os.TempDir(), "govet-overlay-earlier", filepath.Base(filepath.Dir(replacement)), filepath.Base(replacement))
+						}
+					}
+					// the overlay and replacements are only accessible by the current user
+					info, err := os.Stat(filepath.Dir(overlayFile))
+					require.NoError(t, err, "Case %d: %s", i, tc.name)
+					assert.Equal(t, os.FileMode(0700), info.Mode().Perm(), "Case %d: %s", i, tc.name)
+				}
+			}
+			runner.stdout = strings.ReplaceAll(runner.stdout, "$FILE", file)
+		}
+		checker := govet.Checker{
+			VetTool: testVetTool,
+			Runner:  runner,
+			Getwd: func() (string, error) {
+				return wd, nil
+			},
+		}
+
+		buf := &bytes.Buffer{}
+		checker.CheckFile(strings.ReplaceAll(tc.filename, "$WD", wd), overlay, buf)
+		assert.Equal(t, tc.want, buf.String(), "Case %d: %s", i, tc.name)
+
+		require.Len(t, runner.cmds, 1, "Case %d: %s", i, tc.name)
+		assert.Equal(t, "foo/bar", runner.cmds[0].Args[len(runner.cmds[0].Args)-1], "Case %d: %s", i, tc.name)
+
+		// the replacements are copied to the overlay directory with the contents of the replacements
+		require.Len(t, gotOverlay.Replace, len(tc.overlay), "Case %d: %s", i, tc.name)
+		for path, content := range tc.overlay {
+			assert.Equal(t, content, gotContents[filepath.Join(wd, path)], "Case %d: %s", i, tc.name)
+			replacement, ok := gotOverlay.Replace[filepath.Join(wd, path)]
+			require.True(t, ok, "Case %d: %s", i, tc.name)
+			matched, err := filepath.Match(filepath.Join(os.TempDir(), "govet-overlay-*", "*", filepath.Base(path)), replacement)
+			require.NoError(t, err, "Case %d: %s", i, tc.name)
+			assert.True(t, matched, "Case %d: %s: %s", i, tc.name, replacement)
+		}
+
+		// the overlay directory is removed after the run
+		entries, err := os.ReadDir(os.TempDir())
+		require.NoError(t, err, "Case %d: %s", i, tc.name)
+		assert.Empty(t, entries, "Case %d: %s", i, tc.name)
+	}
+}
@@ -0,0 +1,81 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package config
+
+import (
+	"github.com/palantir/godel-okgo-asset-govet/govet"
+	v0 "github.com/palantir/godel-okgo-asset-govet/govet/config/internal/v0"
+	"github.com/palantir/okgo/okgo"
+)
+
+type Govet v0.Config
+
+func (cfg *Govet) ToChecker() (okgo.Checker, error) {
+	if err := govet.ValidateAnnotations(cfg.Annotations); err != nil {
+		return nil, err
+	}
+	if err := govet.ValidateModFallback(cfg.ModFallback); err != nil {
+		return nil, err
+	}
+	env := govet.Env{
+		Set:   cfg.Env.Set,
+		Unset: cfg.Env.Unset,
+	}
+	if err := govet.ValidateEnv(env); err != nil {
+		return nil, err
+	}
+	if err := govet.ValidateCacheAndMemoryLimit(cfg.CacheDir, cfg.MemoryLimit, env); err != nil {
+		return nil, err
+	}
+	if err := govet.ValidateAnalyzers(cfg.Analyzers.Enable, cfg.Analyzers.Disable); err != nil {
+		return nil, err
+	}
+	if err := govet.ValidatePrintfFuncs(cfg.Analyzers.PrintfFuncs); err != nil {
+		return nil, err
+	}
+	var severities []govet.SeverityRule
+	for _, rule := range cfg.Severities {
+		severities = append(severities, govet.SeverityRule(rule))
+	}
+	if err := govet.ValidateSeverities(severities); err != nil {
+		return nil, err
+	}
+	return &govet.Checker{
+		SARIFOutput:          cfg.Reports.SARIF,
+		CheckstyleOutput:     cfg.Reports.Checkstyle,
+		JUnitOutput:          cfg.Reports.JUnit,
+		Annotations:          cfg.Annotations,
+		AnnotationsOutput:    cfg.AnnotationsOutput,
+		EnableAnalyzers:      cfg.Analyzers.Enable,
+		DisableAnalyzers:     cfg.Analyzers.Disable,
+		MatchGoVersion:       cfg.Analyzers.MatchGoVersion,
+		PrintfFuncs:          cfg.Analyzers.PrintfFuncs,
+		Severities:           severities,
+		MaxIssues:            cfg.MaxIssues,
+		MaxIssuesPerAnalyzer: cfg.MaxIssuesPerAnalyzer,
+		Stream:               cfg.Stream,
+		ModFallback:          cfg.ModFallback,
+		Env:                  env,
+		CacheDir:             cfg.CacheDir,
+		MemoryLimit:          cfg.MemoryLimit,
+		Timing: govet.TimingConfig{
+			Enabled:           cfg.Timing.Enabled,
+			Top:               cfg.Timing.Top,
+			JSONOutput:        cfg.Timing.JSON,
+			ChromeTraceOutput: cfg.Timing.ChromeTrace,
+		},
+		Debug: cfg.Debug,
+	}, nil
+}
@@ -15,9 +15,128 @@
 package v0
 
 import (
-	"github.com/palantir/godel/v2/pkg/versionedconfig"
+	"github.com/pkg/errors"
+	"gopkg.in/yaml.v2"
 )
 
+type Config struct {
+	// Reports configures the structured reports that are written in addition to the issues that are provided to okgo.
+	Reports Reports `yaml:"reports,omitempty"`
+	// Annotations is the format of the annotations that are written to a file for findings. The only supported format
+	// is "github", which writes GitHub Actions workflow commands so that findings are shown inline on pull requests.
+	// The GOVET_ASSET_ANNOTATIONS environment variable takes precedence over this value if it is set.
+	Annotations string `yaml:"annotations,omitempty"`
+	// AnnotationsOutput is the path of the file to which annotations are written. Relative paths are resolved against
+	// the project directory. Defaults to "govet-annotations.txt" if empty. The GOVET_ASSET_ANNOTATIONS_OUTPUT
+	// environment variable takes precedence over this value if it is set.
+	AnnotationsOutput string `yaml:"annotations-output,omitempty"`
+	// Analyzers configures which analyzers are run.
+	Analyzers Analyzers `yaml:"analyzers,omitempty"`
+	// Severities are the rules that assign severities to findings. The severity of a finding is the severity of the
+	// first rule that matches it, or "error" if no rule matches. The check fails on any output, so it only outputs the
+	// findings with the "error" severity: findings with the "warning" or "info" severity are not output by the check and
+	// are only included in reports and in the output of the vet-file and watch commands.
+	Severities []Severity `yaml:"severities,omitempty"`
+	// MaxIssues is the maximum number of findings that are written as output of the check. If more findings are
+	// reported, a single summary of the omitted findings grouped by analyzer and package is written instead of them.
+	// Reports include every finding. Unlimited if not positive.
+	MaxIssues int `yaml:"max-issues,omitempty"`
+	// MaxIssuesPerAnalyzer is the maximum number of findings of a single analyzer that are written as output of the
+	// check. Findings beyond the limit are included in the summary of omitted findings. Unlimited if not positive.
+	MaxIssuesPerAnalyzer int `yaml:"max-issues-per-analyzer,omitempty"`
+	// Stream is true if findings are written as soon as "go vet" reports them. By default, findings are written sorted
+	// by file and position after all packages are vetted so that the output is deterministic. Duplicate findings are
+	// omitted in both cases.
+	Stream bool `yaml:"stream,omitempty"`
+	// ModFallback is the value of the -mod build flag with which "go vet" is run again if it fails because the vendor
+	// directory is not consistent with go.mod: "mod" or "readonly". "go vet" is not run again if empty. The vendoring
+	// error is reported along with the errors of running "go vet" again if that fails as well.
+	ModFallback string `yaml:"mod-fallback,omitempty"`
+	// Env configures the environment of the go commands that are run to vet packages, such as "go vet".
+	Env Env `yaml:"env,omitempty"`
+	// CacheDir is the build cache directory of the go commands that are run to vet packages, which is provided to them
+	// as GOCACHE. Relative paths are resolved against the project directory. The shared build cache is used if empty.
+	CacheDir string `yaml:"cache-dir,omitempty"`
+	// MemoryLimit is the soft memory limit of the go commands that are run to vet packages, which is provided to them
+	// as GOMEMLIMIT, such as "2GiB". No limit is set if empty.
+	MemoryLimit string `yaml:"memory-limit,omitempty"`
+	// Timing configures the recording of how long it takes to vet every package.
+	Timing Timing `yaml:"timing,omitempty"`
+	// Debug is true if the go binary, its version, arguments, working directory and relevant environment that are
+	// used to run "go vet", as well as the raw output of "go vet", are written as output of the check. The
+	// GOVET_ASSET_DEBUG environment variable takes precedence over this value if it is set.
+	Debug bool `yaml:"debug,omitempty"`
+}
+
+type Severity struct {
+	// Analyzer is the name of the analyzer whose findings match the rule. Findings of all analyzers match if empty.
+	Analyzer string `yaml:"analyzer,omitempty"`
+	// Message is a regular expression that matches the messages of the findings that match the rule. Findings with any
+	// message match if empty.
+	Message string `yaml:"message,omitempty"`
+	// Severity is the severity of the findings that match the rule: one of "error", "warning" or "info".
+	Severity string `yaml:"severity,omitempty"`
+}
+
+type Env struct {
+	// Set maps the names of environment variables to the values to which they are set. Variables are added to the
+	// environment of the asset or override the values in it.
+	Set map[string]string `yaml:"set,omitempty"`
+	// Unset are the names of the environment variables that are removed from the environment of the asset.
+	Unset []string `yaml:"unset,omitempty"`
+}
+
+type Timing struct {
+	// Enabled is true if the time that it takes to load, type-check and run every analyzer on every package is
+	// recorded. The slowest entries are written as output of the check, which causes the check to fail. Recording
+	// timings disables the vet cache and roughly doubles the time spent loading and type-checking packages.
+	Enabled bool `yaml:"enabled,omitempty"`
+	// Top is the number of slowest entries that are written as output of the check. Defaults to 10.
+	Top int `yaml:"top,omitempty"`
+	// JSON is the path to which all of the recorded entries are written as JSON. Relative paths are resolved against
+	// the project directory.
+	JSON string `yaml:"json,omitempty"`
+	// ChromeTrace is the path to which all of the recorded entries are written in the Chrome trace event format.
+	// Relative paths are resolved against the project directory.
+	ChromeTrace string `yaml:"chrome-trace,omitempty"`
+}
+
+type Analyzers struct {
+	// Enable are the names of the analyzers that are run in addition to the analyzers that are enabled by default.
+	// The analyzers in the "go vet" suite are enabled by default, while the extras bundled with the asset ("nilness",
+	// "shadow" and "unusedwrite") must be enabled explicitly.
+	Enable []string `yaml:"enable,omitempty"`
+	// Disable are the names of the analyzers that are not run even though they are enabled by default.
+	Disable []string `yaml:"disable,omitempty"`
+	// MatchGoVersion is true if the analyzers that are run for every package are adjusted to the Go version declared by
+	// the go directive of its module: "loopclosure" is only run before go1.22, "stdversion" is only run since go1.21
+	// and "waitgroup" is only run since go1.25. Analyzers that are named in Disable are not adjusted, and the check
+	// fails with an error if an analyzer that is named in Enable is not run for the Go version of a module.
+	MatchGoVersion bool `yaml:"match-go-version,omitempty"`
+	// PrintfFuncs are the names of the functions that the "printf" analyzer checks in addition to the functions of the
+	// standard library, the well-known printf wrappers of common libraries and the wrappers that it infers, in the form
+	// accepted by its "funcs" flag: qualified names such as "example.com/log.Infof" or "(*example.com/log.Logger).Infof",
+	// or unqualified names such as "Infof" that match every function with that name regardless of case.
+	PrintfFuncs []string `yaml:"printf-funcs,omitempty"`
+}
+
+type Reports struct {
+	// SARIF is the path to which a SARIF 2.1.0 report of the vet results is written. Relative paths are resolved
+	// against the project directory.
+	SARIF string `yaml:"sarif,omitempty"`
+	// Checkstyle is the path to which a Checkstyle XML report of the vet results is written. Relative paths are
+	// resolved against the project directory.
+	Checkstyle string `yaml:"checkstyle,omitempty"`
+	// JUnit is the path to which a JUnit XML report of the vet results is written. The report contains a test case
+	// for every vetted package that fails if the package has findings. Relative paths are resolved against the project
+	// directory.
+	JUnit string `yaml:"junit,omitempty"`
+}
+
 func UpgradeConfig(cfgBytes []byte) ([]byte, error) {
-	return versionedconfig.ConfigNotSupported("govet-asset", cfgBytes)
+	var cfg Config
+	if err := yaml.UnmarshalStrict(cfgBytes, &cfg); err != nil {
+		return nil, errors.Wrapf(err, "failed to unmarshal govet-asset v0 configuration")
+	}
+	return cfgBytes, nil
 }
@@ -16,17 +16,96 @@ package creator
 
 import (
 	"github.com/palantir/godel-okgo-asset-govet/govet"
+	"github.com/palantir/godel-okgo-asset-govet/govet/config"
 	"github.com/palantir/okgo/checker"
 	"github.com/palantir/okgo/okgo"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+	"gopkg.in/yaml.v2"
 )
 
-func Govet() checker.Creator {
+// Option configures the creator returned by Govet.
+type Option func(*options)
+
+type options struct {
+	analyzers         []*analysis.Analyzer
+	optionalAnalyzers []*analysis.Analyzer
+	defaultConfig     *config.Govet
+}
+
+// WithAnalyzers registers the provided analyzers using govet.RegisterAnalyzers, so that they are enabled by default
+// in addition to the "go vet" suite.
+func WithAnalyzers(analyzerList ...*analysis.Analyzer) Option {
+	return func(o *options) {
+		o.analyzers = append(o.analyzers, analyzerList...)
+	}
+}
+
+// WithOptionalAnalyzers registers the provided analyzers using govet.RegisterOptionalAnalyzers, so that they are
+// available but must be enabled in the configuration.
+func WithOptionalAnalyzers(analyzerList ...*analysis.Analyzer) Option {
+	return func(o *options) {
+		o.optionalAnalyzers = append(o.optionalAnalyzers, analyzerList...)
+	}
+}
+
+// WithDefaultConfig sets the configuration to which the configuration of a project is applied. Values that the
+// project configures replace the default values, except for maps, such as the variables in "env.set", whose entries
+// are merged with the default entries.
+func WithDefaultConfig(cfg config.Govet) Option {
+	return func(o *options) {
+		o.defaultConfig = &cfg
+	}
+}
+
+// Govet returns the creator of the govet checker. The provided options allow building an asset that is govet with
+// additional analyzers and defaults. Analyzers are registered when Govet is called, so an asset that provides
+// analyzers must call Govet before it checks whether it was invoked as a vet tool. If the analyzers cannot be
+// registered, creating a checker returns the error.
+func Govet(opts ...Option) checker.Creator {
+	var o options
+	for _, opt := range opts {
+		opt(&o)
+	}
+	var (
+		defaultConfigYML []byte
+		setupErr         error
+	)
+	if err := govet.RegisterAnalyzers(o.analyzers...); err != nil {
+		setupErr = err
+	} else if err := govet.RegisterOptionalAnalyzers(o.optionalAnalyzers...); err != nil {
+		setupErr = err
+	} else if o.defaultConfig != nil {
+		// the default configuration is stored as YAML so that every checker unmarshals its own copy of it
+		if defaultConfigYML, err = yaml.Marshal(o.defaultConfig); err != nil {
+			setupErr = errors.Wrapf(err, "failed to marshal default configuration")
+		}
+	}
 	return checker.NewCreatorWithMultiCPU(
 		govet.TypeName,
 		govet.Priority,
 		govet.MultiCPU,
 		func(cfgYML []byte) (okgo.Checker, error) {
-			return &govet.Checker{}, nil
+			if setupErr != nil {
+				return nil, setupErr
+			}
+			var cfg config.Govet
+			if err := yaml.UnmarshalStrict(cfgYML, &cfg); err != nil {
+				return nil, errors.Wrapf(err, "failed to unmarshal configuration YAML %q", string(cfgYML))
+			}
+			if defaultConfigYML == nil {
+				return cfg.ToChecker()
+			}
+			// the strict unmarshal validates the configuration, which is then applied to the default configuration. It
+			// cannot be applied strictly because strict unmarshalling rejects map keys that the default already sets.
+			cfg = config.Govet{}
+			if err := yaml.Unmarshal(defaultConfigYML, &cfg); err != nil {
+				return nil, errors.Wrapf(err, "failed to unmarshal default configuration YAML %q", string(defaultConfigYML))
+			}
+			if err := yaml.Unmarshal(cfgYML, &cfg); err != nil {
+				return nil, errors.Wrapf(err, "failed to unmarshal configuration YAML %q", string(cfgYML))
+			}
+			return cfg.ToChecker()
 		},
 	)
 }
@@ -0,0 +1,105 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package creator_test
+
+import (
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet"
+	"github.com/palantir/godel-okgo-asset-govet/govet/config"
+	"github.com/palantir/godel-okgo-asset-govet/govet/creator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestGovetDefaultConfig(t *testing.T) {
+	var defaultConfig config.Govet
+	defaultConfig.Analyzers.Enable = []string{"nilness"}
+	defaultConfig.Env.Set = map[string]string{"CGO_ENABLED": "0", "GOFLAGS": "-mod=vendor"}
+	defaultConfig.MaxIssues = 100
+	newChecker := creator.Govet(creator.WithDefaultConfig(defaultConfig)).Creator()
+
+	for i, tc := range []struct {
+		name      string
+		configYML string
+		want      func(c *govet.Checker)
+	}{
+		{
+			name: "default configuration is used if the project has no configuration",
+			want: func(c *govet.Checker) {
+				c.EnableAnalyzers = []string{"nilness"}
+				c.Env.Set = map[string]string{"CGO_ENABLED": "0", "GOFLAGS": "-mod=vendor"}
+				c.MaxIssues = 100
+			},
+		},
+		{
+			name: "project configuration overrides default values and merges maps",
+			configYML: `
+analyzers:
+  enable: [shadow]
+env:
+  set:
+    GOFLAGS: -mod=mod
+max-issues: 10
+`,
+			want: func(c *govet.Checker) {
+				c.EnableAnalyzers = []string{"shadow"}
+				c.Env.Set = map[string]string{"CGO_ENABLED": "0", "GOFLAGS": "-mod=mod"}
+				c.MaxIssues = 10
+			},
+		},
+	} {
+		checker, err := newChecker([]byte(tc.configYML))
+		require.NoError(t, err, "Case %d: %s", i, tc.name)
+		want := &govet.Checker{}
+		tc.want(want)
+		assert.Equal(t, want, checker, "Case %d: %s", i, tc.name)
+	}
+
+	// the default configuration is not modified by the configurations of projects
+	checker, err := newChecker(nil)
+	require.NoError(t, err)
+	assert.Equal(t, map[string]string{"CGO_ENABLED": "0", "GOFLAGS": "-mod=vendor"}, checker.(*govet.Checker).Env.Set)
+}
+
+func TestGovetAnalyzers(t *testing.T) {
+	newAnalyzer := func(name string) *analysis.Analyzer {
+		return &analysis.Analyzer{
+			Name: name,
+			Doc:  name + ": test analyzer",
+			Run: func(pass *analysis.Pass) (any, error) {
+				return nil, nil
+			},
+		}
+	}
+	newChecker := creator.Govet(
+		creator.WithAnalyzers(newAnalyzer("creatorcustom")),
+		creator.WithOptionalAnalyzers(newAnalyzer("creatoroptional")),
+	).Creator()
+
+	checker, err := newChecker([]byte("analyzers:\n  enable: [creatoroptional]\n"))
+	require.NoError(t, err)
+	infos, err := checker.(*govet.Checker).Analyzers()
+	require.NoError(t, err)
+	require.GreaterOrEqual(t, len(infos), 2)
+	assert.Equal(t, []govet.AnalyzerInfo{
+		{Name: "creatorcustom", Enabled: true, Default: true, Summary: "test analyzer"},
+		{Name: "creatoroptional", Enabled: true, Summary: "test analyzer"},
+	}, infos[len(infos)-2:])
+
+	_, err = creator.Govet(creator.WithAnalyzers(newAnalyzer("printf"))).Creator()(nil)
+	assert.EqualError(t, err, `cannot register analyzer "printf": another analyzer with the same name is available`)
+}
@@ -0,0 +1,135 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"github.com/palantir/okgo/okgo"
+	"github.com/pkg/errors"
+)
+
+// DebugEnvVar is the environment variable that enables debug output. If set, its value must be a boolean, which takes
+// precedence over whether debug output is enabled for the checker.
+const DebugEnvVar = "GOVET_ASSET_DEBUG"
+
+// debugEnvVars are the environment variables whose effective values are written as debug output.
+var debugEnvVars = []string{"GOFLAGS", "GOOS", "GOARCH", "CGO_ENABLED", "GOEXPERIMENT"}
+
+// debugEnabled returns true if debug output is enabled by DebugEnvVar or, if it is not set, by the provided value.
+func debugEnabled(configured bool) (bool, error) {
+	envVal := os.Getenv(DebugEnvVar)
+	if envVal == "" {
+		return configured, nil
+	}
+	enabled, err := strconv.ParseBool(envVal)
+	if err != nil {
+		return false, errors.Errorf("invalid value %q for environment variable %s: must be a boolean", envVal, DebugEnvVar)
+	}
+	return enabled, nil
+}
+
+// writeDebug writes the provided message as an issue that only has content if debug output is enabled.
+func (w *issueWriter) writeDebug(format string, args ...any) {
+	if !w.debug {
+		return
+	}
+	w.writeIssue(okgo.Issue{
+		Content: "govet debug: " + fmt.Sprintf(format, args...),
+	})
+}
+
+// writeInvocationDebug writes the go binary, its version, the arguments, working directory and relevant environment
+// of the provided command as debug output. The version and environment are determined using the provided runner, and
+// the environment includes the changes that the runner makes to it.
+func (w *issueWriter) writeInvocationDebug(ctx context.Context, cmd Command, runner Runner) {
+	if !w.debug {
+		return
+	}
+	// the changes to the environment of the process include those that are configured for the checker
+	changed := cmd
+	if r, ok := runner.(envRunner); ok {
+		changed = r.env.apply(cmd)
+	}
+	goBinary := cmd.Path
+	if resolved, err := lookPath(cmd.Path, environValue(commandEnv(os.Environ(), changed), "PATH")); err == nil {
+		goBinary = resolved
+	}
+	w.writeDebug("go binary: %s", goBinary)
+	if output, err := runOutput(ctx, runner, Command{Path: cmd.Path, Args: []string{"version"}, Dir: cmd.Dir}); err != nil {
+		w.writeDebug("go version: failed to determine version: %v", err)
+	} else {
+		w.writeDebug("go version: %s", strings.TrimSpace(string(output)))
+	}
+	w.writeDebug("args: %q", cmd.Argv())
+	w.writeDebug("working directory: %s", cmd.Dir)
+	for _, name := range changed.Unset {
+		w.writeDebug("env: unset %s", name)
+	}
+	for _, env := range changed.Env {
+		w.writeDebug("env: set %s", env)
+	}
+
+	// the effective values are determined using "go env" so that values from the go env file and defaults are included
+	output, err := runOutput(ctx, runner, Command{
+		Path: cmd.Path,
+		Args: append([]string{"env"}, debugEnvVars...),
+		Dir:  cmd.Dir,
+		Env:  cmd.Env,
+	})
+	if err != nil {
+		w.writeDebug("env: %v", err)
+		return
+	}
+	values := strings.Split(strings.TrimSuffix(string(output), "\n"), "\n")
+	for i, name := range debugEnvVars {
+		if i < len(values) {
+			w.writeDebug("env: %s=%s", name, values[i])
+		}
+	}
+}
+
+// debugLineWriter writes every line written to it as debug output with the provided prefix.
+type debugLineWriter struct {
+	w      *issueWriter
+	prefix string
+	buf    []byte
+}
+
+func (d *debugLineWriter) Write(p []byte) (int, error) {
+	d.buf = append(d.buf, p...)
+	for {
+		idx := bytes.IndexByte(d.buf, '\n')
+		if idx == -1 {
+			break
+		}
+		d.w.writeDebug("%s%s", d.prefix, d.buf[:idx])
+		d.buf = d.buf[idx+1:]
+	}
+	return len(p), nil
+}
+
+// flush writes any remaining partial line.
+func (d *debugLineWriter) flush() {
+	if len(d.buf) > 0 {
+		d.w.writeDebug("%s%s", d.prefix, d.buf)
+		d.buf = nil
+	}
+}
@@ -0,0 +1,156 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet"
+	"github.com/palantir/okgo/okgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestCheckDebug(t *testing.T) {
+	// the go binary is resolved using PATH
+	binDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "go"), []byte("#!/bin/sh\n"), 0755))
+	t.Setenv("PATH", binDir)
+
+	const finding = "fmt.Printf format %s has arg num of wrong type int"
+	// debugLines are the expected debug lines, in which "$WD" is replaced by the working directory, "$BIN" by the
+	// directory of the go binary and "$ARGS" by the arguments of the "go vet" command
+	debugLines := []string{
+		"govet debug: go binary: $BIN/go",
+		"govet debug: go version: go version go1.27.1 linux/amd64",
+		"govet debug: args: $ARGS",
+		"govet debug: working directory: $WD",
+		"govet debug: env: unset GOFLAGS",
+		"govet debug: env: set CGO_ENABLED=0",
+		"govet debug: env: set GOVET_ASSET_VETTOOL=1",
+		"govet debug: env: GOFLAGS=",
+		"govet debug: env: GOOS=linux",
+		"govet debug: env: GOARCH=amd64",
+		"govet debug: env: CGO_ENABLED=0",
+		"govet debug: env: GOEXPERIMENT=",
+		"govet debug: exit status: 0",
+	}
+	for i, tc := range []struct {
+		name       string
+		configured bool
+		envVal     string
+		// want are the contents of the issues that are written, excluding the raw output of "go vet"
+		want []string
+	}{
+		{
+			name: "debug output is not written by default",
+			want: []string{finding},
+		},
+		{
+			name:       "debug output is written if configured",
+			configured: true,
+			want:       append(slices.Clone(debugLines), finding),
+		},
+		{
+			name:   "environment variable enables debug output that is not configured",
+			envVal: "true",
+			want:   append(slices.Clone(debugLines), finding),
+		},
+		{
+			name:       "environment variable disables debug output that is configured",
+			configured: true,
+			envVal:     "0",
+			want:       []string{finding},
+		},
+		{
+			name:       "invalid value of the environment variable is written as an error",
+			configured: true,
+			envVal:     "maybe",
+			want:       []string{`invalid value "maybe" for environment variable GOVET_ASSET_DEBUG: must be a boolean`},
+		},
+	} {
+		t.Setenv(govet.DebugEnvVar, tc.envVal)
+		wd := newModule(t)
+		runner := &debugRunner{fakeRunner: fakeRunner{wd: wd}}
+		runner.loadFixture(t, "test-variants")
+		checker := govet.Checker{
+			Debug: tc.configured,
+			Env: govet.Env{
+				Set:   map[string]string{"CGO_ENABLED": "0"},
+				Unset: []string{"GOFLAGS"},
+			},
+			VetTool: testVetTool,
+			Runner:  runner,
+			Getwd: func() (string, error) {
+				return wd, nil
+			},
+		}
+
+		buf := &bytes.Buffer{}
+		checker.Check([]string{"foo/bar"}, wd, buf)
+
+		var got []string
+		decoder := json.NewDecoder(buf)
+		for decoder.More() {
+			var issue okgo.Issue
+			require.NoError(t, decoder.Decode(&issue), "Case %d: %s", i, tc.name)
+			// the raw output of "go vet" is written as debug output as well
+			if !strings.HasPrefix(issue.Content, "govet debug: stdout: ") && !strings.HasPrefix(issue.Content, "govet debug: stderr: ") {
+				got = append(got, issue.Content)
+			}
+		}
+		var vetArgs string
+		for _, cmd := range runner.cmds {
+			if cmd.Args[0] == "vet" {
+				vetArgs = fmt.Sprintf("%q", cmd.Argv())
+			}
+		}
+		replacer := strings.NewReplacer("$WD", wd, "$BIN", binDir, "$ARGS", vetArgs)
+		var want []string
+		for _, line := range tc.want {
+			want = append(want, replacer.Replace(line))
+		}
+		assert.Equal(t, want, got, "Case %d: %s", i, tc.name)
+	}
+}
+
+// debugRunner is a fakeRunner that outputs a go version and the values of environment variables for the "go version"
+// and "go env" commands that are run to write debug output.
+type debugRunner struct {
+	fakeRunner
+}
+
+func (r *debugRunner) Run(ctx context.Context, cmd govet.Command, stdout, stderr io.Writer) (int, error) {
+	switch cmd.Args[0] {
+	case "version":
+		r.cmds = append(r.cmds, cmd)
+		_, err := io.WriteString(stdout, "go version go1.27.1 linux/amd64\n")
+		return 0, err
+	case "env":
+		r.cmds = append(r.cmds, cmd)
+		_, err := io.WriteString(stdout, "\nlinux\namd64\n0\n\n")
+		return 0, err
+	}
+	return r.fakeRunner.Run(ctx, cmd, stdout, stderr)
+}
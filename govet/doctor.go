@@ -0,0 +1,320 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// DoctorStatus is the outcome of a diagnostic check of the environment.
+type DoctorStatus string
+
+const (
+	// DoctorPass is the status of a check that found no problem.
+	DoctorPass DoctorStatus = "pass"
+	// DoctorFail is the status of a check that found a problem that prevents the asset from vetting packages.
+	DoctorFail DoctorStatus = "fail"
+	// DoctorSkip is the status of a check that could not be performed because a check that it depends on failed.
+	DoctorSkip DoctorStatus = "skip"
+)
+
+// DoctorCheck is the result of a diagnostic check of the environment in which "go vet" is run.
+type DoctorCheck struct {
+	Name   string       `json:"name"`
+	Status DoctorStatus `json:"status"`
+	// Detail describes what the check found.
+	Detail string `json:"detail"`
+	// Hint describes how to fix the problem that a failed check found. Empty for checks that did not fail.
+	Hint string `json:"hint,omitempty"`
+}
+
+// doctorEnvVars are the variables of the go environment that the diagnostic checks use.
+var doctorEnvVars = []string{"GOVERSION", "GOFLAGS", "GOMOD", "GOWORK", "CGO_ENABLED", "CC", "GOCACHE"}
+
+// Doctor diagnoses the environment in which the checker runs "go vet": whether the go binary can be run, whether the
+// working directory is in a module, whether the vendor directory is consistent with the -mod flag in GOFLAGS and with
+// go.mod, whether a C compiler is available if cgo is enabled and whether the build cache is writable. Executables are
+// looked up in the PATH of the configured environment, and the build cache is not created if it does not exist. Checks
+// that depend on the go binary are skipped if it cannot be run.
+func (c *Checker) Doctor() []DoctorCheck {
+	wd, err := c.getwd()
+	if err != nil {
+		return []DoctorCheck{{
+			Name:   "working directory",
+			Status: DoctorFail,
+			Detail: err.Error(),
+			Hint:   "run the asset from an existing directory",
+		}}
+	}
+	ctx := context.Background()
+	opts := c.options(nil, wd, wd)
+	runner := opts.runner()
+	// executables are looked up in the PATH of the go commands rather than that of the process
+	pathEnv := opts.getenv("PATH")
+	goEnv, goCheck := doctorGo(ctx, runner, wd, pathEnv)
+	checks := []DoctorCheck{goCheck}
+	dependents := []struct {
+		name  string
+		check func() DoctorCheck
+	}{
+		{"module", func() DoctorCheck { return doctorModule(goEnv) }},
+		{"vendor", func() DoctorCheck { return doctorVendor(ctx, runner, goEnv) }},
+		{"cgo", func() DoctorCheck { return doctorCgo(goEnv, pathEnv) }},
+		{"build cache", func() DoctorCheck { return doctorBuildCache(goEnv) }},
+	}
+	for _, dependent := range dependents {
+		if goEnv == nil {
+			checks = append(checks, DoctorCheck{
+				Name:   dependent.name,
+				Status: DoctorSkip,
+				Detail: "requires a go binary that can be run",
+			})
+			continue
+		}
+		checks = append(checks, dependent.check())
+	}
+	return checks
+}
+
+// doctorGo checks that the go binary can be run and returns the values of doctorEnvVars in its environment. The go
+// binary is looked up in the provided value of PATH. The returned environment is nil if the go binary cannot be run.
+func doctorGo(ctx context.Context, runner Runner, wd, pathEnv string) (map[string]string, DoctorCheck) {
+	check := DoctorCheck{
+		Name: "go",
+	}
+	output, err := runOutput(ctx, runner, Command{
+		Path: "go",
+		Args: append([]string{"env", "-json"}, doctorEnvVars...),
+		Dir:  wd,
+	})
+	if err != nil {
+		check.Status = DoctorFail
+		check.Detail = err.Error()
+		check.Hint = "install Go and add the directory that contains the go binary to PATH"
+		return nil, check
+	}
+	var goEnv map[string]string
+	if err := json.Unmarshal(output, &goEnv); err != nil {
+		check.Status = DoctorFail
+		check.Detail = errors.Wrapf(err, "failed to parse output of go env").Error()
+		check.Hint = "make sure that the go binary on PATH is the go command"
+		return nil, check
+	}
+	check.Status = DoctorPass
+	check.Detail = goEnv["GOVERSION"]
+	if goPath, err := lookPath("go", pathEnv); err == nil {
+		check.Detail += " (" + goPath + ")"
+	}
+	return goEnv, check
+}
+
+// doctorModule checks that the working directory is in a module or workspace.
+func doctorModule(goEnv map[string]string) DoctorCheck {
+	check := DoctorCheck{
+		Name: "module",
+	}
+	goMod, goWork := goEnv["GOMOD"], goEnv["GOWORK"]
+	switch {
+	case goWork != "" && goWork != "off":
+		check.Status = DoctorPass
+		check.Detail = "workspace " + goWork
+		if goMod != "" && goMod != os.DevNull {
+			check.Detail = fmt.Sprintf("module %s in workspace %s", filepath.Dir(goMod), goWork)
+		}
+	case goMod == "":
+		check.Status = DoctorFail
+		check.Detail = "modules are disabled because GO111MODULE=off"
+		check.Hint = "unset GO111MODULE so that packages are resolved in their modules"
+	case goMod == os.DevNull:
+		check.Status = DoctorFail
+		check.Detail = "the working directory is not in a module"
+		check.Hint = `run the asset in the directory of a module or one of its subdirectories, or create a module using "go mod init"`
+	default:
+		check.Status = DoctorPass
+		check.Detail = "module " + filepath.Dir(goMod)
+	}
+	return check
+}
+
+// doctorVendor checks that the vendor directory of the module or workspace is consistent with the -mod flag in
+// GOFLAGS and, if it is used, with go.mod.
+func doctorVendor(ctx context.Context, runner Runner, goEnv map[string]string) DoctorCheck {
+	check := DoctorCheck{
+		Name: "vendor",
+	}
+	root, vendorCmd := "", "go mod vendor"
+	if goWork := goEnv["GOWORK"]; goWork != "" && goWork != "off" {
+		root, vendorCmd = filepath.Dir(goWork), "go work vendor"
+	} else if goMod := goEnv["GOMOD"]; goMod != "" && goMod != os.DevNull {
+		root = filepath.Dir(goMod)
+	} else {
+		check.Status = DoctorSkip
+		check.Detail = "requires a module"
+		return check
+	}
+	modFlag := goFlagsModFlag(goEnv["GOFLAGS"])
+	modulesTxt := filepath.Join(root, "vendor", "modules.txt")
+	if _, err := os.Stat(modulesTxt); err != nil {
+		if modFlag == "vendor" {
+			check.Status = DoctorFail
+			check.Detail = fmt.Sprintf("GOFLAGS contains -mod=vendor, but %s does not exist", modulesTxt)
+			check.Hint = fmt.Sprintf("run %q in %s or remove -mod=vendor from GOFLAGS", vendorCmd, root)
+			return check
+		}
+		check.Status = DoctorPass
+		check.Detail = "no vendor directory"
+		return check
+	}
+	if modFlag != "" && modFlag != "vendor" {
+		check.Status = DoctorPass
+		check.Detail = fmt.Sprintf("the vendor directory is ignored because GOFLAGS contains -mod=%s", modFlag)
+		return check
+	}
+	// the go command verifies that vendor/modules.txt is consistent with go.mod whenever it loads the module graph
+	stderr := &bytes.Buffer{}
+	exitCode, err := runner.Run(ctx, Command{
+		Path: "go",
+		Args: []string{"list", "-mod=vendor", "-m"},
+		Dir:  root,
+	}, &bytes.Buffer{}, stderr)
+	if err == nil && exitCode != 0 {
+		err = errors.New(firstParagraph(stderr.String()))
+	}
+	if err != nil {
+		check.Status = DoctorFail
+		check.Detail = err.Error()
+		check.Hint = fmt.Sprintf("run %q in %s to sync the vendor directory or set GOFLAGS=-mod=mod to ignore it", vendorCmd, root)
+		return check
+	}
+	check.Status = DoctorPass
+	check.Detail = modulesTxt + " is consistent with go.mod"
+	return check
+}
+
+// doctorCgo checks that a C compiler is available in the provided value of PATH if cgo is enabled.
+func doctorCgo(goEnv map[string]string, pathEnv string) DoctorCheck {
+	check := DoctorCheck{
+		Name: "cgo",
+	}
+	if goEnv["CGO_ENABLED"] != "1" {
+		check.Status = DoctorPass
+		check.Detail = `disabled, so files that import "C" are not vetted`
+		return check
+	}
+	// CC may contain flags in addition to the compiler
+	cc := strings.Fields(goEnv["CC"])
+	if len(cc) == 0 {
+		cc = []string{"cc"}
+	}
+	ccPath, err := lookPath(cc[0], pathEnv)
+	if err != nil {
+		check.Status = DoctorFail
+		check.Detail = fmt.Sprintf("enabled, but the C compiler %s cannot be found: %v", cc[0], err)
+		check.Hint = "install a C compiler, set CC to the path of one or set CGO_ENABLED=0"
+		return check
+	}
+	check.Status = DoctorPass
+	check.Detail = fmt.Sprintf("enabled with C compiler %s", ccPath)
+	return check
+}
+
+// doctorBuildCache checks that the build cache, which "go vet" requires, is writable.
+func doctorBuildCache(goEnv map[string]string) DoctorCheck {
+	check := DoctorCheck{
+		Name: "build cache",
+	}
+	goCache := goEnv["GOCACHE"]
+	if goCache == "" || goCache == "off" {
+		check.Status = DoctorFail
+		check.Detail = "the build cache is disabled or its location cannot be determined"
+		check.Hint = "set GOCACHE to a writable directory"
+		return check
+	}
+	if err := checkWritable(goCache); err != nil {
+		check.Status = DoctorFail
+		check.Detail = err.Error()
+		check.Hint = "set GOCACHE to a writable directory or fix the permissions of " + goCache
+		return check
+	}
+	check.Status = DoctorPass
+	check.Detail = goCache + " is writable"
+	if _, err := os.Stat(goCache); os.IsNotExist(err) {
+		check.Detail = goCache + " does not exist, but can be created"
+	}
+	return check
+}
+
+// checkWritable returns an error if a file cannot be created in the provided directory. If the directory does not
+// exist, it is not created: the nearest existing ancestor must be a directory in which it can be created.
+func checkWritable(dir string) error {
+	existing := dir
+	for {
+		info, err := os.Stat(existing)
+		if err == nil {
+			if !info.IsDir() {
+				return errors.Errorf("%s is not a directory", existing)
+			}
+			break
+		}
+		// a file in the path of the directory is reported once it is reached
+		if !os.IsNotExist(err) && !errors.Is(err, syscall.ENOTDIR) {
+			return errors.Wrapf(err, "failed to check %s", existing)
+		}
+		parent := filepath.Dir(existing)
+		if parent == existing {
+			return errors.Wrapf(err, "failed to check %s", dir)
+		}
+		existing = parent
+	}
+	f, err := os.CreateTemp(existing, "govet-doctor-")
+	if err != nil {
+		return errors.Wrapf(err, "failed to create file in %s", existing)
+	}
+	_ = f.Close()
+	return os.Remove(f.Name())
+}
+
+// goFlagsModFlag returns the value of the -mod flag in the provided value of GOFLAGS. Returns the empty string if the
+// flag is not set.
+func goFlagsModFlag(goFlags string) string {
+	modFlag := ""
+	for _, flag := range strings.Fields(goFlags) {
+		flag = strings.TrimPrefix(strings.TrimPrefix(flag, "-"), "-")
+		if val, ok := strings.CutPrefix(flag, "mod="); ok {
+			// the last occurrence of a flag takes precedence
+			modFlag = val
+		}
+	}
+	return modFlag
+}
+
+// firstParagraph returns the lines of the provided output up to the first empty line with surrounding whitespace
+// trimmed.
+func firstParagraph(output string) string {
+	output = strings.TrimSpace(output)
+	if idx := strings.Index(output, "\n\n"); idx != -1 {
+		output = output[:idx]
+	}
+	return output
+}
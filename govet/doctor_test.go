@@ -0,0 +1,274 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet_test
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDoctor(t *testing.T) {
+	const inconsistentVendoring = `go: inconsistent vendoring in $WD:
+	github.com/pkg/errors@v0.9.1: is explicitly required in go.mod, but not marked as explicit in vendor/modules.txt
+
+	To ignore the vendor directory, use -mod=readonly or -mod=mod.
+	To sync the vendor directory, run:
+		go mod vendor
+`
+	for i, tc := range []struct {
+		name string
+		// env overrides the values of the go environment that the fake go command reports
+		env    map[string]string
+		envErr error
+		// configuredBin are the names of the executables in a directory that is set as PATH in the configured
+		// environment of the checker. PATH is not configured if nil.
+		configuredBin []string
+		vendor        bool
+		listExit      int
+		listOut       string
+		want          []govet.DoctorStatus
+		wantCheck     govet.DoctorCheck
+	}{
+		{
+			name: "healthy environment",
+			want: []govet.DoctorStatus{govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorPass},
+			wantCheck: govet.DoctorCheck{
+				Name:   "go",
+				Status: govet.DoctorPass,
+				Detail: "go1.24.0 ($BIN/go)",
+			},
+		},
+		{
+			name:   "go binary cannot be run",
+			envErr: errors.New(`exec: "go": executable file not found in $PATH`),
+			want:   []govet.DoctorStatus{govet.DoctorFail, govet.DoctorSkip, govet.DoctorSkip, govet.DoctorSkip, govet.DoctorSkip},
+			wantCheck: govet.DoctorCheck{
+				Name:   "go",
+				Status: govet.DoctorFail,
+				Detail: `failed to run command [go env -json GOVERSION GOFLAGS GOMOD GOWORK CGO_ENABLED CC GOCACHE]: exec: "go": executable file not found in $PATH`,
+				Hint:   "install Go and add the directory that contains the go binary to PATH",
+			},
+		},
+		{
+			name: "working directory not in a module",
+			env:  map[string]string{"GOMOD": os.DevNull},
+			want: []govet.DoctorStatus{govet.DoctorPass, govet.DoctorFail, govet.DoctorSkip, govet.DoctorPass, govet.DoctorPass},
+		},
+		{
+			name: "workspace",
+			env:  map[string]string{"GOMOD": os.DevNull, "GOWORK": "$WD/go.work"},
+			want: []govet.DoctorStatus{govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorPass},
+			wantCheck: govet.DoctorCheck{
+				Name:   "module",
+				Status: govet.DoctorPass,
+				Detail: "workspace $WD/go.work",
+			},
+		},
+		{
+			name: "-mod=vendor without vendor directory",
+			env:  map[string]string{"GOFLAGS": "-mod=vendor"},
+			want: []govet.DoctorStatus{govet.DoctorPass, govet.DoctorPass, govet.DoctorFail, govet.DoctorPass, govet.DoctorPass},
+			wantCheck: govet.DoctorCheck{
+				Name:   "vendor",
+				Status: govet.DoctorFail,
+				Detail: "GOFLAGS contains -mod=vendor, but $WD/vendor/modules.txt does not exist",
+				Hint:   `run "go mod vendor" in $WD or remove -mod=vendor from GOFLAGS`,
+			},
+		},
+		{
+			name:   "consistent vendor directory",
+			vendor: true,
+			want:   []govet.DoctorStatus{govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorPass},
+		},
+		{
+			name:     "inconsistent vendor directory",
+			vendor:   true,
+			listExit: 1,
+			listOut:  inconsistentVendoring,
+			want:     []govet.DoctorStatus{govet.DoctorPass, govet.DoctorPass, govet.DoctorFail, govet.DoctorPass, govet.DoctorPass},
+			wantCheck: govet.DoctorCheck{
+				Name:   "vendor",
+				Status: govet.DoctorFail,
+				Detail: "go: inconsistent vendoring in $WD:\n\tgithub.com/pkg/errors@v0.9.1: is explicitly required in go.mod, but not marked as explicit in vendor/modules.txt",
+				Hint:   `run "go mod vendor" in $WD to sync the vendor directory or set GOFLAGS=-mod=mod to ignore it`,
+			},
+		},
+		{
+			name:     "inconsistent vendor directory ignored by -mod=mod",
+			env:      map[string]string{"GOFLAGS": "-mod=mod"},
+			vendor:   true,
+			listExit: 1,
+			listOut:  inconsistentVendoring,
+			want:     []govet.DoctorStatus{govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorPass},
+		},
+		{
+			name: "cgo enabled without C compiler",
+			env:  map[string]string{"CC": "missing-cc -m64"},
+			want: []govet.DoctorStatus{govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorFail, govet.DoctorPass},
+		},
+		{
+			name: "cgo disabled without C compiler",
+			env:  map[string]string{"CC": "missing-cc", "CGO_ENABLED": "0"},
+			want: []govet.DoctorStatus{govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorPass},
+		},
+		{
+			name:          "go binary is looked up in the configured PATH",
+			configuredBin: []string{"go", "cc"},
+			want:          []govet.DoctorStatus{govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorPass},
+			wantCheck: govet.DoctorCheck{
+				Name:   "go",
+				Status: govet.DoctorPass,
+				Detail: "go1.24.0 ($CONFIGURED_BIN/go)",
+			},
+		},
+		{
+			name:          "C compiler is looked up in the configured PATH",
+			configuredBin: []string{"go"},
+			want:          []govet.DoctorStatus{govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorFail, govet.DoctorPass},
+			wantCheck: govet.DoctorCheck{
+				Name:   "cgo",
+				Status: govet.DoctorFail,
+				Detail: `enabled, but the C compiler cc cannot be found: exec: "cc": executable file not found in $PATH`,
+				Hint:   "install a C compiler, set CC to the path of one or set CGO_ENABLED=0",
+			},
+		},
+		{
+			name: "build cache that does not exist in a writable directory",
+			env:  map[string]string{"GOCACHE": "$WD/cache/go-build"},
+			want: []govet.DoctorStatus{govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorPass},
+			wantCheck: govet.DoctorCheck{
+				Name:   "build cache",
+				Status: govet.DoctorPass,
+				Detail: "$WD/cache/go-build does not exist, but can be created",
+			},
+		},
+		{
+			name: "build cache in a file",
+			env:  map[string]string{"GOCACHE": "$WD/go.mod/go-build"},
+			want: []govet.DoctorStatus{govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorFail},
+			wantCheck: govet.DoctorCheck{
+				Name:   "build cache",
+				Status: govet.DoctorFail,
+				Detail: "$WD/go.mod is not a directory",
+				Hint:   "set GOCACHE to a writable directory or fix the permissions of $WD/go.mod/go-build",
+			},
+		},
+		{
+			name: "build cache disabled",
+			env:  map[string]string{"GOCACHE": "off"},
+			want: []govet.DoctorStatus{govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorPass, govet.DoctorFail},
+		},
+	} {
+		wd := newModule(t)
+		binDir := newBinDir(t, "go", "cc")
+		t.Setenv("PATH", binDir)
+
+		goEnv := map[string]string{
+			"GOVERSION":   "go1.24.0",
+			"GOFLAGS":     "",
+			"GOMOD":       filepath.Join(wd, "go.mod"),
+			"GOWORK":      "",
+			"CGO_ENABLED": "1",
+			"CC":          "cc",
+			"GOCACHE":     filepath.Join(t.TempDir(), "cache"),
+		}
+		for k, v := range tc.env {
+			goEnv[k] = strings.ReplaceAll(v, "$WD", wd)
+		}
+		goEnvJSON, err := json.Marshal(goEnv)
+		require.NoError(t, err)
+		if tc.vendor {
+			require.NoError(t, os.MkdirAll(filepath.Join(wd, "vendor"), 0755))
+			require.NoError(t, os.WriteFile(filepath.Join(wd, "vendor", "modules.txt"), nil, 0644))
+		}
+		runner := subcommandRunner{
+			"env": &fakeRunner{
+				stdout: string(goEnvJSON),
+				err:    tc.envErr,
+			},
+			"list": &fakeRunner{
+				stderr:   strings.ReplaceAll(tc.listOut, "$WD", wd),
+				exitCode: tc.listExit,
+			},
+		}
+		checker := govet.Checker{
+			Runner: runner,
+			Getwd: func() (string, error) {
+				return wd, nil
+			},
+		}
+		configuredBinDir := ""
+		if tc.configuredBin != nil {
+			configuredBinDir = newBinDir(t, tc.configuredBin...)
+			checker.Env.Set = map[string]string{"PATH": configuredBinDir}
+		}
+
+		checks := checker.Doctor()
+		var statuses []govet.DoctorStatus
+		for _, check := range checks {
+			statuses = append(statuses, check.Status)
+		}
+		assert.Equal(t, tc.want, statuses, "Case %d: %s", i, tc.name)
+		// the build cache is not created
+		if goCache := goEnv["GOCACHE"]; goCache != "off" {
+			_, err := os.Stat(goCache)
+			assert.Error(t, err, "Case %d: %s", i, tc.name)
+		}
+		if tc.wantCheck.Name == "" {
+			continue
+		}
+		wantCheck := tc.wantCheck
+		replacer := strings.NewReplacer("$WD", wd, "$BIN", binDir, "$CONFIGURED_BIN", configuredBinDir)
+		wantCheck.Detail = replacer.Replace(wantCheck.Detail)
+		wantCheck.Hint = replacer.Replace(wantCheck.Hint)
+		var gotCheck govet.DoctorCheck
+		for _, check := range checks {
+			if check.Name == wantCheck.Name {
+				gotCheck = check
+			}
+		}
+		assert.Equal(t, wantCheck, gotCheck, "Case %d: %s", i, tc.name)
+	}
+}
+
+// subcommandRunner is a govet.Runner that runs go commands using the runner for their subcommand.
+type subcommandRunner map[string]*fakeRunner
+
+func (r subcommandRunner) Run(ctx context.Context, cmd govet.Command, stdout, stderr io.Writer) (int, error) {
+	runner, ok := r[cmd.Args[0]]
+	if !ok {
+		return -1, errors.Errorf("unexpected command %v", cmd.Argv())
+	}
+	return runner.Run(ctx, cmd, stdout, stderr)
+}
+
+// newBinDir returns a temporary directory that contains executables with the provided names.
+func newBinDir(t *testing.T, names ...string) string {
+	binDir := t.TempDir()
+	for _, name := range names {
+		require.NoError(t, os.WriteFile(filepath.Join(binDir, name), []byte("#!/bin/sh\n"), 0755))
+	}
+	return binDir
+}
@@ -0,0 +1,183 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet
+
+import (
+	"context"
+	"io"
+	"maps"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/srcpos"
+	"github.com/pkg/errors"
+)
+
+// Env configures the environment of the go commands that the checker runs, such as "go vet". The commands inherit the
+// environment of the process with the configured changes applied.
+type Env struct {
+	// Set maps the names of environment variables to the values to which they are set. Variables that are not in the
+	// environment of the process are added, while variables that are in it are overridden.
+	Set map[string]string
+	// Unset are the names of the environment variables that are removed from the environment of the process.
+	Unset []string
+}
+
+const (
+	goCacheEnvVar    = "GOCACHE"
+	goMemLimitEnvVar = "GOMEMLIMIT"
+)
+
+var (
+	// envNameRegexp matches valid names of environment variables.
+	envNameRegexp = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+	// memoryLimitRegexp matches the values of GOMEMLIMIT: a number of bytes with an optional unit, or "off".
+	memoryLimitRegexp = regexp.MustCompile(`^(off|[0-9]+(B|KiB|MiB|GiB|TiB)?)$`)
+)
+
+// ValidateEnv returns an error if the provided environment configuration sets or unsets an invalid name, sets and
+// unsets the same variable, or changes a variable that the checker uses to run its vet tool.
+func ValidateEnv(env Env) error {
+	unset := make(map[string]struct{})
+	for _, name := range env.Unset {
+		if err := validateEnvName(name); err != nil {
+			return err
+		}
+		unset[name] = struct{}{}
+	}
+	for _, name := range env.sortedSetNames() {
+		if err := validateEnvName(name); err != nil {
+			return err
+		}
+		if _, ok := unset[name]; ok {
+			return errors.Errorf("environment variable %s is both set and unset", name)
+		}
+	}
+	return nil
+}
+
+// ValidateCacheAndMemoryLimit returns an error if the provided memory limit is not a valid value of GOMEMLIMIT or if
+// the provided environment configuration changes a variable that is determined by the provided cache directory or
+// memory limit.
+func ValidateCacheAndMemoryLimit(cacheDir, memoryLimit string, env Env) error {
+	if memoryLimit != "" && !memoryLimitRegexp.MatchString(memoryLimit) {
+		return errors.Errorf("invalid memory limit %q: must be a number of bytes with an optional unit of B, KiB, MiB, GiB or TiB, or \"off\"", memoryLimit)
+	}
+	for _, option := range []struct {
+		option, value, envVar string
+	}{
+		{"cache directory", cacheDir, goCacheEnvVar},
+		{"memory limit", memoryLimit, goMemLimitEnvVar},
+	} {
+		if option.value == "" {
+			continue
+		}
+		if _, ok := env.Set[option.envVar]; ok || slices.Contains(env.Unset, option.envVar) {
+			return errors.Errorf("environment variable %s cannot be configured because it is determined by the %s", option.envVar, option.option)
+		}
+	}
+	return nil
+}
+
+func validateEnvName(name string) error {
+	if !envNameRegexp.MatchString(name) {
+		return errors.Errorf("invalid environment variable name %q", name)
+	}
+	if name == vetToolEnvVar {
+		return errors.Errorf("environment variable %s is used by the checker and cannot be configured", name)
+	}
+	return nil
+}
+
+// env returns the configuration of the environment of the go commands that are run for the options, which includes the
+// cache directory and memory limit of the options.
+func (o Options) env() Env {
+	if o.CacheDir == "" && o.MemoryLimit == "" {
+		return o.Env
+	}
+	env := Env{
+		Set:   maps.Clone(o.Env.Set),
+		Unset: o.Env.Unset,
+	}
+	if env.Set == nil {
+		env.Set = make(map[string]string)
+	}
+	if o.CacheDir != "" {
+		// the go command requires GOCACHE to be an absolute path
+		env.Set[goCacheEnvVar] = resolvePath(o.CacheDir, o.Dir)
+	}
+	if o.MemoryLimit != "" {
+		env.Set[goMemLimitEnvVar] = o.MemoryLimit
+	}
+	return env
+}
+
+// positionResolver returns the resolver for the positions that are reported by the go commands that are run for the
+// options. The build cache of the commands is the cache directory of the options or the one in the environment of the
+// process.
+func (o Options) positionResolver() srcpos.Resolver {
+	return srcpos.Resolver{CacheDir: o.getenv(goCacheEnvVar)}
+}
+
+// getenv returns the value of the environment variable with the provided name in the environment of the go commands
+// that are run for the options, which is the environment of the process with the configured changes applied.
+func (o Options) getenv(name string) string {
+	env := o.env()
+	if value, ok := env.Set[name]; ok {
+		return value
+	}
+	if slices.Contains(env.Unset, name) {
+		return ""
+	}
+	return os.Getenv(name)
+}
+
+// empty returns true if the configuration does not change the environment.
+func (e Env) empty() bool {
+	return len(e.Set) == 0 && len(e.Unset) == 0
+}
+
+// apply returns the provided command with the configured changes to its environment. Variables that the command
+// sets itself take precedence over the configured values.
+func (e Env) apply(cmd Command) Command {
+	var env []string
+	for _, name := range e.sortedSetNames() {
+		env = append(env, name+"="+e.Set[name])
+	}
+	cmd.Env = append(env, cmd.Env...)
+	cmd.Unset = append(append([]string(nil), e.Unset...), cmd.Unset...)
+	return cmd
+}
+
+func (e Env) sortedSetNames() []string {
+	var names []string
+	for name := range e.Set {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// envRunner is a Runner that runs commands with the configured changes to their environment.
+type envRunner struct {
+	runner Runner
+	env    Env
+}
+
+func (r envRunner) Run(ctx context.Context, cmd Command, stdout, stderr io.Writer) (int, error) {
+	return r.runner.Run(ctx, r.env.apply(cmd), stdout, stderr)
+}
@@ -0,0 +1,215 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet_test
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestValidateEnv(t *testing.T) {
+	for i, tc := range []struct {
+		name    string
+		env     govet.Env
+		wantErr string
+	}{
+		{
+			name: "valid",
+			env: govet.Env{
+				Set:   map[string]string{"CGO_ENABLED": "0", "GOFLAGS": "-mod=mod"},
+				Unset: []string{"GOEXPERIMENT"},
+			},
+		},
+		{
+			name: "invalid name",
+			env: govet.Env{
+				Set: map[string]string{"CGO ENABLED": "0"},
+			},
+			wantErr: `invalid environment variable name "CGO ENABLED"`,
+		},
+		{
+			name: "set and unset",
+			env: govet.Env{
+				Set:   map[string]string{"GOFLAGS": "-mod=mod"},
+				Unset: []string{"GOFLAGS"},
+			},
+			wantErr: "environment variable GOFLAGS is both set and unset",
+		},
+		{
+			name: "variable used by the checker",
+			env: govet.Env{
+				Unset: []string{"GOVET_ASSET_VETTOOL"},
+			},
+			wantErr: "environment variable GOVET_ASSET_VETTOOL is used by the checker and cannot be configured",
+		},
+	} {
+		err := govet.ValidateEnv(tc.env)
+		if tc.wantErr == "" {
+			assert.NoError(t, err, "Case %d: %s", i, tc.name)
+		} else {
+			assert.EqualError(t, err, tc.wantErr, "Case %d: %s", i, tc.name)
+		}
+	}
+}
+
+func TestCheckEnv(t *testing.T) {
+	wd := newModule(t)
+	runner := &fakeRunner{wd: wd}
+	runner.loadFixture(t, "test-variants")
+	checker := govet.Checker{
+		VetTool: testVetTool,
+		Runner:  runner,
+		Env: govet.Env{
+			Set:   map[string]string{"GOFLAGS": "-mod=mod", "CGO_ENABLED": "0"},
+			Unset: []string{"GOEXPERIMENT"},
+		},
+		Getwd: func() (string, error) {
+			return wd, nil
+		},
+	}
+	checker.Check([]string{"foo/bar"}, wd, io.Discard)
+
+	require.Len(t, runner.cmds, 1)
+	assert.Equal(t, []string{"CGO_ENABLED=0", "GOFLAGS=-mod=mod", "GOVET_ASSET_VETTOOL=1"}, runner.cmds[0].Env)
+	assert.Equal(t, []string{"GOEXPERIMENT"}, runner.cmds[0].Unset)
+}
+
+func TestValidateCacheAndMemoryLimit(t *testing.T) {
+	for i, tc := range []struct {
+		name        string
+		cacheDir    string
+		memoryLimit string
+		env         govet.Env
+		wantErr     string
+	}{
+		{
+			name:        "valid",
+			cacheDir:    "out/cache",
+			memoryLimit: "2GiB",
+			env: govet.Env{
+				Set: map[string]string{"GOFLAGS": "-mod=mod"},
+			},
+		},
+		{
+			name:        "memory limit without unit",
+			memoryLimit: "1073741824",
+		},
+		{
+			name:        "memory limit off",
+			memoryLimit: "off",
+		},
+		{
+			name:        "invalid memory limit",
+			memoryLimit: "2GB",
+			wantErr:     `invalid memory limit "2GB": must be a number of bytes with an optional unit of B, KiB, MiB, GiB or TiB, or "off"`,
+		},
+		{
+			name:     "cache directory and GOCACHE",
+			cacheDir: "out/cache",
+			env: govet.Env{
+				Set: map[string]string{"GOCACHE": "/tmp/cache"},
+			},
+			wantErr: "environment variable GOCACHE cannot be configured because it is determined by the cache directory",
+		},
+		{
+			name:        "memory limit and unset GOMEMLIMIT",
+			memoryLimit: "2GiB",
+			env: govet.Env{
+				Unset: []string{"GOMEMLIMIT"},
+			},
+			wantErr: "environment variable GOMEMLIMIT cannot be configured because it is determined by the memory limit",
+		},
+		{
+			name: "GOCACHE without cache directory",
+			env: govet.Env{
+				Set: map[string]string{"GOCACHE": "/tmp/cache"},
+			},
+		},
+	} {
+		err := govet.ValidateCacheAndMemoryLimit(tc.cacheDir, tc.memoryLimit, tc.env)
+		if tc.wantErr == "" {
+			assert.NoError(t, err, "Case %d: %s", i, tc.name)
+		} else {
+			assert.EqualError(t, err, tc.wantErr, "Case %d: %s", i, tc.name)
+		}
+	}
+}
+
+func TestCheckCacheAndMemoryLimit(t *testing.T) {
+	wd := newModule(t)
+	runner := &fakeRunner{wd: wd}
+	runner.loadFixture(t, "test-variants")
+	checker := govet.Checker{
+		VetTool:     testVetTool,
+		Runner:      runner,
+		CacheDir:    "out/cache",
+		MemoryLimit: "2GiB",
+		Env: govet.Env{
+			Set: map[string]string{"CGO_ENABLED": "0"},
+		},
+		Getwd: func() (string, error) {
+			return wd, nil
+		},
+	}
+	checker.Check([]string{"foo/bar"}, wd, io.Discard)
+
+	require.Len(t, runner.cmds, 1)
+	assert.Equal(t, []string{
+		"CGO_ENABLED=0",
+		"GOCACHE=" + filepath.Join(wd, "out", "cache"),
+		"GOMEMLIMIT=2GiB",
+		"GOVET_ASSET_VETTOOL=1",
+	}, runner.cmds[0].Env)
+}
+
+func TestExecRunnerEnv(t *testing.T) {
+	t.Setenv("GOVET_TEST_SET", "inherited")
+	t.Setenv("GOVET_TEST_OVERRIDE", "inherited")
+	t.Setenv("GOVET_TEST_UNSET", "inherited")
+
+	stdout := &bytes.Buffer{}
+	exitCode, err := govet.ExecRunner{}.Run(context.Background(), govet.Command{
+		Path:  "sh",
+		Args:  []string{"-c", `echo "$GOVET_TEST_SET,$GOVET_TEST_OVERRIDE,${GOVET_TEST_UNSET-unset}"`},
+		Env:   []string{"GOVET_TEST_OVERRIDE=overridden"},
+		Unset: []string{"GOVET_TEST_UNSET"},
+	}, stdout, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, "inherited,overridden,unset\n", stdout.String())
+}
+
+func TestExecRunnerPath(t *testing.T) {
+	binDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(binDir, "govet-test-cmd"), []byte("#!/bin/sh\necho configured\n"), 0755))
+
+	// the executable is looked up in the PATH of the command rather than that of the process
+	stdout := &bytes.Buffer{}
+	exitCode, err := govet.ExecRunner{}.Run(context.Background(), govet.Command{
+		Path: "govet-test-cmd",
+		Env:  []string{"PATH=" + binDir + string(filepath.ListSeparator) + os.Getenv("PATH")},
+	}, stdout, io.Discard)
+	require.NoError(t, err)
+	assert.Equal(t, 0, exitCode)
+	assert.Equal(t, "configured\n", stdout.String())
+}
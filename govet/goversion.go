@@ -0,0 +1,168 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"slices"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/analyzers"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+)
+
+// GoVersionAdjustment is a change to the analyzers that are run for the packages of a module that is made because of
+// the Go version declared by the go directive of the module.
+type GoVersionAdjustment struct {
+	Module    string `json:"module"`
+	GoVersion string `json:"goVersion"`
+	Analyzer  string `json:"analyzer"`
+	// Enabled is true if the analyzer is run even though it is not enabled by the configuration, and false if the
+	// analyzer is not run even though it is enabled by the configuration.
+	Enabled bool   `json:"enabled"`
+	Reason  string `json:"reason"`
+}
+
+// listedPackage is a package as listed by "go list".
+type listedPackage struct {
+	ImportPath string
+	Module     *struct {
+		Path      string
+		GoVersion string
+	}
+}
+
+// goVersionAnalyzers returns the analyzers that are run for each of the packages matched by the provided import paths
+// and patterns when the provided analyzers are selected, keyed by import path, along with the adjustments that were
+// made for each module. Packages are listed in the directory of the options using the provided runner. Returns nil if
+// the options do not match analyzers to Go versions. Returns an error if an analyzer that is enabled by the options is
+// not run for the Go version of a module, since such an analyzer would otherwise be run without notice.
+func (o Options) goVersionAnalyzers(ctx context.Context, runner Runner, pkgPaths []string, selected []*analysis.Analyzer) (map[string][]*analysis.Analyzer, []GoVersionAdjustment, error) {
+	if !o.MatchGoVersion {
+		return nil, nil, nil
+	}
+	pkgs, err := listModules(ctx, runner, o.Dir, pkgPaths)
+	if err != nil {
+		return nil, nil, err
+	}
+	explicit := append(slices.Clone(o.EnableAnalyzers), o.DisableAnalyzers...)
+	pkgAnalyzers := make(map[string][]*analysis.Analyzer)
+	var adjustments []GoVersionAdjustment
+	adjustedModules := make(map[string]bool)
+	for _, pkg := range pkgs {
+		if pkg.Module == nil {
+			pkgAnalyzers[pkg.ImportPath] = selected
+			continue
+		}
+		goVersion := analyzers.GoVersion(pkg.Module.GoVersion)
+		adjusted, moduleAdjustments := analyzers.ForGoVersion(analyzers.All(), selected, goVersion, explicit)
+		pkgAnalyzers[pkg.ImportPath] = adjusted
+		if adjustedModules[pkg.Module.Path] {
+			continue
+		}
+		adjustedModules[pkg.Module.Path] = true
+		for _, name := range o.EnableAnalyzers {
+			if reason := analyzers.Unsupported(name, goVersion); reason != "" {
+				return nil, nil, errors.Errorf("analyzer %q is enabled, but does not apply to module %s with Go version %s because %s: remove it from the enable list or disable match-go-version",
+					name, pkg.Module.Path, goVersion, reason)
+			}
+		}
+		for _, adjustment := range moduleAdjustments {
+			adjustments = append(adjustments, GoVersionAdjustment{
+				Module:    pkg.Module.Path,
+				GoVersion: goVersion,
+				Analyzer:  adjustment.Analyzer,
+				Enabled:   adjustment.Enabled,
+				Reason:    adjustment.Reason,
+			})
+		}
+	}
+	return pkgAnalyzers, adjustments, nil
+}
+
+// matchGoVersion configures the provided run to vet every package with the analyzers that match the Go version of its
+// module and returns the adjustments that were made. The run keeps its packages if all of them are vetted with the same
+// analyzers and otherwise vets the individual packages that they match.
+func (o Options) matchGoVersion(ctx context.Context, run *vetRun) ([]GoVersionAdjustment, error) {
+	pkgAnalyzers, adjustments, err := o.goVersionAnalyzers(ctx, run.runner, run.pkgPaths, run.analyzers)
+	if err != nil || len(adjustments) == 0 {
+		return nil, err
+	}
+	var (
+		importPaths []string
+		common      []*analysis.Analyzer
+		same        = true
+	)
+	for importPath, analyzerList := range pkgAnalyzers {
+		if len(importPaths) == 0 {
+			common = analyzerList
+		} else if !slices.Equal(common, analyzerList) {
+			same = false
+		}
+		importPaths = append(importPaths, importPath)
+	}
+	if same {
+		run.analyzers = common
+		return adjustments, nil
+	}
+	slices.Sort(importPaths)
+	run.pkgPaths = importPaths
+	run.pkgAnalyzers = pkgAnalyzers
+	return adjustments, nil
+}
+
+// writeGoVersionAdjustments writes the provided adjustments as debug output.
+func writeGoVersionAdjustments(adjustments []GoVersionAdjustment, w *issueWriter) {
+	for _, adjustment := range adjustments {
+		w.writeDebug("%s", adjustment.String())
+	}
+}
+
+// String returns a description of the adjustment.
+func (a GoVersionAdjustment) String() string {
+	action := "disabled"
+	if a.Enabled {
+		action = "enabled"
+	}
+	return a.Module + " (" + a.GoVersion + "): " + action + " " + a.Analyzer + " because " + a.Reason
+}
+
+// listModules returns the packages matched by the provided import paths and patterns in wd along with their modules.
+func listModules(ctx context.Context, runner Runner, wd string, pkgPaths []string) ([]listedPackage, error) {
+	cmd := Command{
+		Path: "go",
+		Args: append([]string{"list", "-e", "-json=ImportPath,Module"}, pkgPaths...),
+		Dir:  wd,
+	}
+	output, err := runOutput(ctx, runner, cmd)
+	if err != nil {
+		return nil, err
+	}
+	var pkgs []listedPackage
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var pkg listedPackage
+		if err := decoder.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode output of command %v", cmd.Argv())
+		}
+		pkgs = append(pkgs, pkg)
+	}
+	return pkgs, nil
+}
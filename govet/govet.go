@@ -15,16 +15,20 @@
 package govet
 
 import (
-	"fmt"
+	"bytes"
+	"context"
 	"io"
 	"os"
-	"os/exec"
-	"regexp"
-	"strings"
+	"path/filepath"
+	"time"
 
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/limit"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/report"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/timing"
 	"github.com/palantir/okgo/checker"
 	"github.com/palantir/okgo/okgo"
 	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
 )
 
 const (
@@ -33,7 +37,135 @@ const (
 	MultiCPU okgo.CheckerMultiCPU = true
 )
 
-type Checker struct{}
+const (
+	// AnnotationsEnvVar is the environment variable that selects the annotation format. If set, it takes precedence
+	// over the format configured for the checker.
+	AnnotationsEnvVar = "GOVET_ASSET_ANNOTATIONS"
+	// AnnotationsOutputEnvVar is the environment variable that sets the path of the file to which annotations are
+	// written. If set, it takes precedence over the path configured for the checker.
+	AnnotationsOutputEnvVar = "GOVET_ASSET_ANNOTATIONS_OUTPUT"
+	// DefaultAnnotationsOutput is the path, relative to the project directory, of the file to which annotations are
+	// written if no path is configured.
+	DefaultAnnotationsOutput = "govet-annotations.txt"
+
+	// AnnotationsGitHub is the annotation format that writes a GitHub Actions workflow command for every finding so
+	// that findings are shown inline on pull requests.
+	AnnotationsGitHub = "github"
+)
+
+// ValidateAnnotations returns an error if the provided annotation format is not supported. The empty string, which
+// disables annotations, is supported.
+func ValidateAnnotations(annotations string) error {
+	switch annotations {
+	case "", AnnotationsGitHub:
+		return nil
+	default:
+		return errors.Errorf("unsupported annotation format %q: must be %q or empty", annotations, AnnotationsGitHub)
+	}
+}
+
+// ValidateModFallback returns an error if the provided value of the -mod build flag cannot be used to run "go vet"
+// again after a vendoring error. The empty string, which disables running "go vet" again, is supported.
+func ValidateModFallback(modFallback string) error {
+	switch modFallback {
+	case "", "mod", "readonly":
+		return nil
+	default:
+		return errors.Errorf("unsupported mod fallback %q: must be %q, %q or empty", modFallback, "mod", "readonly")
+	}
+}
+
+type Checker struct {
+	// SARIFOutput is the path to which a SARIF report of the vet results is written. Relative paths are resolved
+	// against the project directory. No report is written if empty.
+	SARIFOutput string
+	// CheckstyleOutput is the path to which a Checkstyle XML report of the vet results is written. Relative paths are
+	// resolved against the project directory. No report is written if empty.
+	CheckstyleOutput string
+	// JUnitOutput is the path to which a JUnit XML report of the vet results is written. Relative paths are resolved
+	// against the project directory. No report is written if empty.
+	JUnitOutput string
+	// Annotations is the format of the annotations that are written for findings. No annotations are written if empty.
+	// Overridden by the value of AnnotationsEnvVar if it is set. Annotations are written to a file rather than as
+	// output of the check so that the issues are unaffected.
+	Annotations string
+	// AnnotationsOutput is the path of the file to which annotations are written if they are enabled. Relative paths are
+	// resolved against the project directory. Defaults to DefaultAnnotationsOutput if empty. Overridden by the value of
+	// AnnotationsOutputEnvVar if it is set.
+	AnnotationsOutput string
+	// EnableAnalyzers are the names of the analyzers that are run in addition to the analyzers that are enabled by
+	// default.
+	EnableAnalyzers []string
+	// DisableAnalyzers are the names of the analyzers that are not run even though they are enabled by default.
+	DisableAnalyzers []string
+	// MatchGoVersion is true if the analyzers that are run for every package are adjusted to the Go version declared by
+	// the go directive of its module. Analyzers that are named in DisableAnalyzers are not adjusted, and vetting fails
+	// with an error if an analyzer that is named in EnableAnalyzers is not run for the Go version of a module.
+	MatchGoVersion bool
+	// PrintfFuncs are the names of the functions that the printf analyzer checks in addition to the functions that it
+	// knows, infers or that are well-known printf wrappers.
+	PrintfFuncs []string
+	// Severities are the rules that assign severities to findings. The severity of a finding is the severity of the
+	// first rule that matches it, or SeverityError if no rule matches. Only findings with the SeverityError severity
+	// are written as issues, since any issue fails the check. Findings with other severities are not written by Check
+	// and are only included in reports.
+	Severities []SeverityRule
+	// MaxIssues is the maximum number of findings that are written as issues. If more findings are reported, a single
+	// issue that summarizes the omitted findings is written after all other issues. Reports include every finding.
+	// Unlimited if not positive.
+	MaxIssues int
+	// MaxIssuesPerAnalyzer is the maximum number of findings of a single analyzer that are written as issues. Findings
+	// beyond the limit are summarized in the same manner as those beyond MaxIssues. Unlimited if not positive.
+	MaxIssuesPerAnalyzer int
+	// Stream is true if issues are written as soon as "go vet" reports them. Otherwise, issues are written sorted by
+	// file and position after all packages are vetted, which makes the output deterministic at the cost of not
+	// writing any issues until vetting completes. Duplicate findings are omitted in both cases.
+	Stream bool
+	// ModFallback is the value of the -mod build flag with which "go vet" is run again for packages for which it fails
+	// because the vendor directory is not consistent with go.mod, such as "mod" or "readonly". The vendoring error is
+	// reported if empty or if running "go vet" again fails as well.
+	ModFallback string
+	// Timing configures the recording of how long it takes to vet every package.
+	Timing TimingConfig
+	// VetTool is the path to the vet tool that "go vet" uses to run the analyzers. The vet tool must run the analyzers of
+	// the asset when invoked with the GOVET_ASSET_VETTOOL environment variable set. Defaults to the executable of the
+	// current process, which is the asset, if it called IsVetToolInvocation, and must be set otherwise.
+	VetTool string
+	// Env configures the environment of the go commands that the checker runs, such as "go vet".
+	Env Env
+	// CacheDir is the build cache directory of the go commands that the checker runs, which is provided to them as
+	// GOCACHE. A dedicated cache avoids contention on the shared build cache with commands that run concurrently.
+	// Relative paths are resolved against the project directory. The shared build cache is used if empty.
+	CacheDir string
+	// MemoryLimit is the soft memory limit of the go commands that the checker runs and of the vet tool, which is
+	// provided to them as GOMEMLIMIT, such as "2GiB". No limit is set if empty.
+	MemoryLimit string
+	// Runner runs the commands of the checker, such as "go vet". Defaults to ExecRunner.
+	Runner Runner
+	// Getwd returns the working directory relative to which packages are resolved and in which commands are run.
+	// Defaults to os.Getwd.
+	Getwd func() (string, error)
+	// Debug is true if the go binary, its version, arguments, working directory and relevant environment that are
+	// used to run "go vet", as well as the raw output of "go vet", are written as issues. Overridden by the value of
+	// DebugEnvVar if it is set.
+	Debug bool
+}
+
+// TimingConfig configures the recording of how long it takes to load, type-check and run every analyzer on every
+// package.
+type TimingConfig struct {
+	// Enabled is true if timings are recorded. The slowest entries are written as a single issue after all other
+	// issues. Recording timings disables the vet cache and loads and type-checks every package twice.
+	Enabled bool
+	// Top is the number of slowest entries that are written. Defaults to 10 if not positive.
+	Top int
+	// JSONOutput is the path to which all of the recorded entries are written as JSON. Relative paths are resolved
+	// against the project directory. Not written if empty.
+	JSONOutput string
+	// ChromeTraceOutput is the path to which all of the recorded entries are written in the Chrome trace event format.
+	// Relative paths are resolved against the project directory. Not written if empty.
+	ChromeTraceOutput string
+}
 
 func (c *Checker) Type() (okgo.CheckerType, error) {
 	return TypeName, nil
@@ -47,47 +179,202 @@ func (c *Checker) MultiCPU() (okgo.CheckerMultiCPU, error) {
 	return MultiCPU, nil
 }
 
-var lineRegexp = regexp.MustCompile(`(.+):(\d+): (.+)`)
-
 func (c *Checker) Check(pkgPaths []string, projectDir string, stdout io.Writer) {
-	wd, err := os.Getwd()
+	w := &issueWriter{
+		stdout: stdout,
+	}
+	wd, err := c.getwd()
+	if err != nil {
+		w.writeError(err)
+		return
+	}
+	if projectDir == "" {
+		projectDir = wd
+	}
+
+	annotations := c.Annotations
+	if envVal := os.Getenv(AnnotationsEnvVar); envVal != "" {
+		annotations = envVal
+	}
+	if err := ValidateAnnotations(annotations); err != nil {
+		w.writeError(err)
+		return
+	}
+	if annotations == AnnotationsGitHub {
+		w.annotations = &bytes.Buffer{}
+		w.annotationBaseDir = annotationBaseDir(projectDir)
+		defer c.writeAnnotations(w, projectDir)
+	}
+	if w.debug, err = debugEnabled(c.Debug); err != nil {
+		w.writeError(err)
+		return
+	}
+
+	// okgo considers every issue a failure, so findings with other severities are only included in reports
+	w.errorsOnly = true
+
+	var timingDir string
+	if c.Timing.Enabled {
+		timingDir, err = os.MkdirTemp("", "govet-timing-")
+		if err != nil {
+			w.writeError(errors.Wrapf(err, "failed to create directory for timings"))
+			return
+		}
+		defer func() {
+			_ = os.RemoveAll(timingDir)
+		}()
+	}
+	if c.MaxIssues > 0 || c.MaxIssuesPerAnalyzer > 0 {
+		w.limiter = &limit.Limiter{
+			Max:            c.MaxIssues,
+			MaxPerAnalyzer: c.MaxIssuesPerAnalyzer,
+		}
+	}
+	start := time.Now()
+	ctx := context.Background()
+	run, results, err := c.options(pkgPaths, wd, projectDir).vet(ctx, w, func(run *vetRun) {
+		run.stream = c.Stream
+		if timingDir != "" {
+			run.flags = append(run.flags, "-"+timingDirFlagName+"="+timingDir)
+		}
+	})
 	if err != nil {
-		okgo.WriteErrorAsIssue(errors.Wrapf(err, "failed to determine working directory"), stdout)
+		w.writeError(err)
+		return
+	}
+	if len(run.pkgPaths) == 0 {
 		return
 	}
+	if w.limiter != nil {
+		if summary := w.limiter.Summary(); summary != "" {
+			w.writeIssue(okgo.Issue{
+				Content: summary,
+			})
+		}
+	}
+	if c.Timing.Enabled {
+		c.writeTimings(timingDir, time.Since(start), projectDir, w)
+	}
+	if c.JUnitOutput != "" {
+		// the JUnit report includes a test case for every package, including those without findings
+		vettedPkgs, err := listPackages(ctx, run.runner, wd, run.pkgPaths)
+		if err != nil {
+			w.writeError(errors.Wrapf(err, "failed to determine packages for JUnit report"))
+		}
+		results.Packages = vettedPkgs
+	}
+	c.writeReports(results, run.allAnalyzers(), projectDir, w)
+}
 
-	// go vet does not accept package paths that start with "./.." because they are not considered canonical paths. Deal
-	// with this specific case manually by converting paths that start with "./.." to start with "..".
-	cleanedPaths := make([]string, len(pkgPaths))
-	for i, v := range pkgPaths {
-		if strings.HasPrefix(v, "./..") {
-			v = strings.TrimPrefix(v, "./")
+// writeTimings writes the timings that were recorded to the provided directory by the vet tool.
+func (c *Checker) writeTimings(timingDir string, total time.Duration, projectDir string, w *issueWriter) {
+	entries, err := timing.ReadDir(timingDir)
+	if err != nil {
+		w.writeError(err)
+		return
+	}
+	for _, currReport := range []struct {
+		path  string
+		write func(out io.Writer) error
+	}{
+		{
+			path: c.Timing.JSONOutput,
+			write: func(out io.Writer) error {
+				return timing.WriteJSON(out, entries)
+			},
+		},
+		{
+			path: c.Timing.ChromeTraceOutput,
+			write: func(out io.Writer) error {
+				return timing.WriteChromeTrace(out, entries)
+			},
+		},
+	} {
+		if currReport.path == "" {
+			continue
 		}
-		cleanedPaths[i] = v
-	}
-	pkgPaths = cleanedPaths
-
-	cmd := exec.Command("go", append(
-		[]string{"vet"},
-		pkgPaths...)...,
-	)
-	checker.RunCommandAndStreamOutput(cmd, func(line string) okgo.Issue {
-		// if govet finds issue, it ends with the output "exit status 1", but we don't want to include it as part of the output
-		if line == "exit status 1" {
-			return okgo.Issue{}
+		if err := report.WriteFile(resolvePath(currReport.path, projectDir), currReport.write); err != nil {
+			w.writeError(err)
 		}
-		// ignore output in the form of comments
-		if strings.HasPrefix(line, "#") {
-			return okgo.Issue{}
+	}
+	top := c.Timing.Top
+	if top <= 0 {
+		top = 10
+	}
+	w.writeIssue(okgo.Issue{
+		Content: timing.FormatSlowest(entries, top, total),
+	})
+}
+
+// writeReports writes the configured reports for the provided results of running the provided analyzers.
+func (c *Checker) writeReports(results report.Results, analyzerList []*analysis.Analyzer, projectDir string, w *issueWriter) {
+	for _, currReport := range []struct {
+		path  string
+		write func(out io.Writer) error
+	}{
+		{
+			path: c.SARIFOutput,
+			write: func(out io.Writer) error {
+				return report.WriteSARIF(out, results, analyzerList, projectDir)
+			},
+		},
+		{
+			path: c.CheckstyleOutput,
+			write: func(out io.Writer) error {
+				return report.WriteCheckstyle(out, results, projectDir)
+			},
+		},
+		{
+			path: c.JUnitOutput,
+			write: func(out io.Writer) error {
+				return report.WriteJUnit(out, results, projectDir)
+			},
+		},
+	} {
+		if currReport.path == "" {
+			continue
 		}
-		if match := lineRegexp.FindStringSubmatch(line); match != nil {
-			// Checker does not include column info, so add it in manually
-			line = fmt.Sprintf("%s:%s:0: %s", match[1], match[2], match[3])
+		if err := report.WriteFile(resolvePath(currReport.path, projectDir), currReport.write); err != nil {
+			w.writeError(err)
 		}
-		return okgo.NewIssueFromLine(line, wd)
-	}, stdout)
+	}
 }
 
 func (c *Checker) RunCheckCmd(args []string, stdout io.Writer) {
 	checker.AmalgomatedRunRawCheck(string(TypeName), args, stdout)
 }
+
+// writeAnnotations writes the annotations that were collected by the provided writer to the configured file. The file
+// is written even if there are no annotations so that it does not retain the annotations of a previous run.
+func (c *Checker) writeAnnotations(w *issueWriter, projectDir string) {
+	output := c.AnnotationsOutput
+	if envVal := os.Getenv(AnnotationsOutputEnvVar); envVal != "" {
+		output = envVal
+	}
+	if output == "" {
+		output = DefaultAnnotationsOutput
+	}
+	if err := report.WriteFile(resolvePath(output, projectDir), func(out io.Writer) error {
+		_, err := out.Write(w.annotations.Bytes())
+		return err
+	}); err != nil {
+		w.writeError(err)
+	}
+}
+
+// annotationBaseDir returns the directory relative to which file names in annotations are written. GitHub Actions
+// expects file names relative to the root of the repository, which is the workspace directory if it is set.
+func annotationBaseDir(projectDir string) string {
+	if workspace := os.Getenv("GITHUB_WORKSPACE"); workspace != "" {
+		return workspace
+	}
+	return projectDir
+}
+
+// resolvePath returns the provided path resolved against baseDir if it is relative.
+func resolvePath(path, baseDir string) string {
+	if filepath.IsAbs(path) {
+		return path
+	}
+	return filepath.Join(baseDir, path)
+}
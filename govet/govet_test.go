@@ -0,0 +1,426 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet_test
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet"
+	"github.com/pkg/errors"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/txtar"
+)
+
+const testVetTool = "/path/govet-asset"
+
+func TestCheck(t *testing.T) {
+	for i, tc := range []struct {
+		name    string
+		checker govet.Checker
+		// fixture is the name of the archive in testdata/vet that contains the recorded output of "go vet"
+		fixture string
+		// retryFixture is the name of the archive that contains the recorded output of "go vet" when it is run again
+		retryFixture string
+		runErr       error
+		getwdErr     error
+		pkgs         []string
+		// want is the expected output, in which "$WD" is replaced by the working directory, "$ARGS" by the arguments of
+		// the "go vet" command and "$RETRY_ARGS" by the arguments of the "go vet" command that is run again
+		want string
+	}{
+		{
+			name:    "findings and type errors are written sorted with paths relative to the working directory",
+			fixture: "findings",
+			pkgs:    []string{"foo/bar", "foo/broken", "foo/tt"},
+			want: `{"path":"bar/bar.go","line":11,"col":14,"content":"fmt.Printf format %s has arg num of wrong type int"}
+{"path":"bar/bar.go","line":13,"col":8,"content":"assignment copies lock value to m2: sync.Mutex"}
+{"path":"bar/bar.go","line":14,"col":6,"content":"assignment copies lock value to _: sync.Mutex"}
+{"path":"broken/b.go","line":3,"col":12,"content":"declared and not used: x"}
+{"path":"tt/a_test.go","line":3,"col":39,"content":"fmt.Printf format %d has arg \"s\" of wrong type string"}
+{"path":"tt/b_test.go","line":3,"col":50,"content":"declared and not used: x"}
+`,
+		},
+		{
+			name:    "paths of files in the working directory are prefixed with ./ like those that go vet prints",
+			fixture: "root-package",
+			pkgs:    []string{"foo"},
+			want: `{"path":"./foo.go","line":7,"col":14,"content":"fmt.Printf format %s has arg num of wrong type int"}
+`,
+		},
+		{
+			name: "findings that are not errors are not written",
+			checker: govet.Checker{
+				Severities: []govet.SeverityRule{
+					{Analyzer: "copylocks", Severity: govet.SeverityWarning},
+				},
+			},
+			fixture: "findings",
+			pkgs:    []string{"foo/bar", "foo/broken", "foo/tt"},
+			want: `{"path":"bar/bar.go","line":11,"col":14,"content":"fmt.Printf format %s has arg num of wrong type int"}
+{"path":"broken/b.go","line":3,"col":12,"content":"declared and not used: x"}
+{"path":"tt/a_test.go","line":3,"col":39,"content":"fmt.Printf format %d has arg \"s\" of wrong type string"}
+{"path":"tt/b_test.go","line":3,"col":50,"content":"declared and not used: x"}
+`,
+		},
+		{
+			name: "only errors that are not findings are written if every finding is a warning or info",
+			checker: govet.Checker{
+				Severities: []govet.SeverityRule{
+					{Analyzer: "printf", Message: "wrong type", Severity: govet.SeverityInfo},
+					{Severity: govet.SeverityWarning},
+				},
+			},
+			fixture: "findings",
+			pkgs:    []string{"foo/bar", "foo/broken", "foo/tt"},
+			want: `{"path":"broken/b.go","line":3,"col":12,"content":"declared and not used: x"}
+{"path":"tt/b_test.go","line":3,"col":50,"content":"declared and not used: x"}
+`,
+		},
+		{
+			name:    "finding reported by a package and its test variant is written once",
+			fixture: "test-variants",
+			pkgs:    []string{"foo/bar"},
+			want: `{"path":"bar/bar.go","line":11,"col":14,"content":"fmt.Printf format %s has arg num of wrong type int"}
+`,
+		},
+		{
+			name:    "analyzer failure is written as an error",
+			fixture: "analyzer-error",
+			pkgs:    []string{"foo/bar"},
+			want: `{"path":"","line":0,"col":0,"content":"foo/bar: analyzer printf failed: internal error: unexpected type"}
+`,
+		},
+		{
+			name:    "type error details are written with the error and progress is not written",
+			fixture: "type-error-details",
+			pkgs:    []string{"foo/args"},
+			want: `{"path":"args/a.go","line":5,"col":14,"content":"not enough arguments in call to f\n\thave ()\n\twant (int)"}
+`,
+		},
+		{
+			name:    "vendoring error is written as a single error with its fix",
+			fixture: "inconsistent-vendoring",
+			pkgs:    []string{"foo/bar"},
+			want: `{"path":"","line":0,"col":0,"content":"inconsistent vendoring in $WD:\n\tgithub.com/pkg/errors@v0.9.1: is explicitly required in go.mod, but not marked as explicit in vendor/modules.txt\n\tgithub.com/pkg/errors@v0.8.0: is marked as explicit in vendor/modules.txt, but not explicitly required in go.mod\n\tto fix: run \"go mod vendor\" to sync the vendor directory with go.mod, or set -mod=mod or -mod=readonly to ignore the vendor directory"}
+`,
+		},
+		{
+			name: "vendoring error is not written if vetting with the fallback -mod flag succeeds",
+			checker: govet.Checker{
+				ModFallback: "mod",
+			},
+			fixture:      "inconsistent-vendoring",
+			retryFixture: "test-variants",
+			pkgs:         []string{"foo/bar"},
+			want: `{"path":"bar/bar.go","line":11,"col":14,"content":"fmt.Printf format %s has arg num of wrong type int"}
+`,
+		},
+		{
+			name: "vendoring error is written if vetting with the fallback -mod flag fails",
+			checker: govet.Checker{
+				ModFallback: "readonly",
+			},
+			fixture:      "inconsistent-vendoring",
+			retryFixture: "exit-without-stderr",
+			pkgs:         []string{"foo/bar"},
+			want: `{"path":"","line":0,"col":0,"content":"failed to run command $RETRY_ARGS: exit status 2"}
+{"path":"","line":0,"col":0,"content":"inconsistent vendoring in $WD:\n\tgithub.com/pkg/errors@v0.9.1: is explicitly required in go.mod, but not marked as explicit in vendor/modules.txt\n\tgithub.com/pkg/errors@v0.8.0: is marked as explicit in vendor/modules.txt, but not explicitly required in go.mod\n\tto fix: run \"go mod vendor\" to sync the vendor directory with go.mod, or set -mod=mod or -mod=readonly to ignore the vendor directory"}
+`,
+		},
+		{
+			name: "vendoring error is written if the go command is killed while vetting with the fallback -mod flag",
+			checker: govet.Checker{
+				ModFallback: "mod",
+			},
+			fixture:      "inconsistent-vendoring",
+			retryFixture: "go-killed",
+			pkgs:         []string{"foo/broken"},
+			want: `{"path":"","line":0,"col":0,"content":"failed to run command $RETRY_ARGS: killed (exit status 137), most likely because the system ran out of memory; set memory-limit so that garbage is collected before memory runs out, run fewer checks in parallel or vet fewer packages at once"}
+{"path":"","line":0,"col":0,"content":"inconsistent vendoring in $WD:\n\tgithub.com/pkg/errors@v0.9.1: is explicitly required in go.mod, but not marked as explicit in vendor/modules.txt\n\tgithub.com/pkg/errors@v0.8.0: is marked as explicit in vendor/modules.txt, but not explicitly required in go.mod\n\tto fix: run \"go mod vendor\" to sync the vendor directory with go.mod, or set -mod=mod or -mod=readonly to ignore the vendor directory"}
+{"path":"broken/b.go","line":3,"col":12,"content":"declared and not used: x"}
+`,
+		},
+		{
+			name:    "non-zero exit code without output is written as an error",
+			fixture: "exit-without-stderr",
+			pkgs:    []string{"foo/bar"},
+			want: `{"path":"","line":0,"col":0,"content":"failed to run command $ARGS: exit status 2"}
+`,
+		},
+		{
+			name:    "findings in generated files that cannot be mapped to source files are written without a position",
+			fixture: "generated-positions",
+			pkgs:    []string{"foo/cg"},
+			want: `{"path":"","line":0,"col":0,"content":"undefined: _Ctype_struct_foo (reported at _cgo_gotypes.go:20:9, which could not be mapped to a source file)"}
+{"path":"","line":0,"col":0,"content":"result of fmt.Sprint call not used (reported at _cgo_gotypes.go:12:3, which could not be mapped to a source file)"}
+`,
+		},
+		{
+			name:    "packages for which the vet tool was killed are written as a single error",
+			fixture: "vettool-killed",
+			pkgs:    []string{"./..."},
+			want: `{"path":"","line":0,"col":0,"content":"the vet tool was killed while vetting 5 package(s) (foo/bar, foo/baz, foo/broken and 2 more), most likely because the system ran out of memory; set memory-limit so that garbage is collected before memory runs out, run fewer checks in parallel or vet fewer packages at once"}
+`,
+		},
+		{
+			name:    "vet killed by a signal is written as an error even if it wrote errors",
+			fixture: "go-killed",
+			pkgs:    []string{"foo/broken"},
+			want: `{"path":"","line":0,"col":0,"content":"failed to run command $ARGS: killed (exit status 137), most likely because the system ran out of memory; set memory-limit so that garbage is collected before memory runs out, run fewer checks in parallel or vet fewer packages at once"}
+{"path":"broken/b.go","line":3,"col":12,"content":"declared and not used: x"}
+`,
+		},
+		{
+			name:   "failure to run the command is written as an error",
+			runErr: errors.New(`exec: "go": executable file not found in $PATH`),
+			pkgs:   []string{"foo/bar"},
+			want: `{"path":"","line":0,"col":0,"content":"failed to run command $ARGS: exec: \"go\": executable file not found in $PATH"}
+`,
+		},
+		{
+			name:     "failure to determine the working directory is written as an error",
+			getwdErr: errors.New("getwd: no such file or directory"),
+			pkgs:     []string{"foo/bar"},
+			want: `{"path":"","line":0,"col":0,"content":"failed to determine working directory: getwd: no such file or directory"}
+`,
+		},
+	} {
+		wd := newModule(t)
+		runner := &fakeRunner{
+			wd:  wd,
+			err: tc.runErr,
+		}
+		if tc.fixture != "" {
+			runner.loadFixture(t, tc.fixture)
+		}
+		if tc.retryFixture != "" {
+			runner.retry = &fakeRunner{wd: wd}
+			runner.retry.loadFixture(t, tc.retryFixture)
+		}
+		checker := tc.checker
+		checker.VetTool = testVetTool
+		checker.Runner = runner
+		checker.Getwd = func() (string, error) {
+			return wd, tc.getwdErr
+		}
+
+		buf := &bytes.Buffer{}
+		checker.Check(tc.pkgs, wd, buf)
+
+		want := strings.ReplaceAll(tc.want, "$WD", wd)
+		for cmdIdx, placeholder := range []string{"$ARGS", "$RETRY_ARGS"} {
+			if len(runner.cmds) > cmdIdx {
+				want = strings.ReplaceAll(want, placeholder, fmt.Sprint(runner.cmds[cmdIdx].Argv()))
+			}
+		}
+		assert.Equal(t, want, buf.String(), "Case %d: %s", i, tc.name)
+	}
+}
+
+func TestCheckCommand(t *testing.T) {
+	wd := newModule(t)
+	runner := &fakeRunner{wd: wd}
+	runner.loadFixture(t, "test-variants")
+	checker := govet.Checker{
+		VetTool: testVetTool,
+		Runner:  runner,
+		Getwd: func() (string, error) {
+			return wd, nil
+		},
+	}
+	checker.Check([]string{"foo/bar", "foo/tt"}, wd, io.Discard)
+
+	require.Len(t, runner.cmds, 1)
+	cmd := runner.cmds[0]
+	assert.Equal(t, "go", cmd.Path)
+	assert.Equal(t, []string{"vet", "-vettool=" + testVetTool, "-json"}, cmd.Args[:3])
+	assert.Equal(t, []string{"foo/bar", "foo/tt"}, cmd.Args[len(cmd.Args)-2:])
+	assert.Equal(t, wd, cmd.Dir)
+	assert.Equal(t, []string{"GOVET_ASSET_VETTOOL=1"}, cmd.Env)
+}
+
+func TestCheckModFallback(t *testing.T) {
+	wd := newModule(t)
+	runner := &fakeRunner{wd: wd}
+	runner.loadFixture(t, "inconsistent-vendoring")
+	runner.retry = &fakeRunner{wd: wd}
+	runner.retry.loadFixture(t, "test-variants")
+	checker := govet.Checker{
+		VetTool:     testVetTool,
+		Runner:      runner,
+		ModFallback: "mod",
+		Getwd: func() (string, error) {
+			return wd, nil
+		},
+	}
+	checker.Check([]string{"foo/bar"}, wd, io.Discard)
+
+	require.Len(t, runner.cmds, 2)
+	assert.NotContains(t, runner.cmds[0].Args, "-mod=mod")
+	assert.Equal(t, []string{"vet", "-vettool=" + testVetTool, "-json", "-mod=mod"}, runner.cmds[1].Args[:4])
+}
+
+func TestCheckAnnotations(t *testing.T) {
+	t.Setenv("GITHUB_WORKSPACE", "")
+	const (
+		finding = `{"path":"bar/bar.go","line":11,"col":14,"content":"fmt.Printf format %s has arg num of wrong type int"}
+`
+		annotation = "::error file=bar/bar.go,line=11,col=14,endLine=11,endColumn=16,title=printf::fmt.Printf format %25s has arg num of wrong type int\n"
+	)
+	for i, tc := range []struct {
+		name              string
+		annotations       string
+		annotationsOutput string
+		envVal            string
+		outputEnvVal      string
+		want              string
+		// wantFiles maps the paths of the annotation files that are expected to be written, relative to the working
+		// directory, to their contents
+		wantFiles map[string]string
+	}{
+		{
+			name: "annotations are not written by default",
+			want: finding,
+		},
+		{
+			name:        "annotations are written to the default file if configured and the issues are unaffected",
+			annotations: "github",
+			want:        finding,
+			wantFiles:   map[string]string{"govet-annotations.txt": annotation},
+		},
+		{
+			name:              "annotations are written to the configured file",
+			annotations:       "github",
+			annotationsOutput: "out/annotations.txt",
+			want:              finding,
+			wantFiles:         map[string]string{"out/annotations.txt": annotation},
+		},
+		{
+			name:              "environment variables enable annotations and set the file",
+			annotationsOutput: "out/annotations.txt",
+			envVal:            "github",
+			outputEnvVal:      "ci/annotations.txt",
+			want:              finding,
+			wantFiles:         map[string]string{"ci/annotations.txt": annotation},
+		},
+		{
+			name:        "environment variable takes precedence over the configured format",
+			annotations: "github",
+			envVal:      "gitlab",
+			want: `{"path":"","line":0,"col":0,"content":"unsupported annotation format \"gitlab\": must be \"github\" or empty"}
+`,
+		},
+	} {
+		t.Setenv(govet.AnnotationsEnvVar, tc.envVal)
+		t.Setenv(govet.AnnotationsOutputEnvVar, tc.outputEnvVal)
+		wd := newModule(t)
+		runner := &fakeRunner{wd: wd}
+		runner.loadFixture(t, "test-variants")
+		checker := govet.Checker{
+			Annotations:       tc.annotations,
+			AnnotationsOutput: tc.annotationsOutput,
+			VetTool:           testVetTool,
+			Runner:            runner,
+			Getwd: func() (string, error) {
+				return wd, nil
+			},
+		}
+
+		buf := &bytes.Buffer{}
+		checker.Check([]string{"foo/bar"}, wd, buf)
+		assert.Equal(t, tc.want, buf.String(), "Case %d: %s", i, tc.name)
+		for _, path := range []string{"govet-annotations.txt", "out/annotations.txt", "ci/annotations.txt"} {
+			content, err := os.ReadFile(filepath.Join(wd, path))
+			if want, ok := tc.wantFiles[path]; ok {
+				require.NoError(t, err, "Case %d: %s", i, tc.name)
+				assert.Equal(t, want, string(content), "Case %d: %s", i, tc.name)
+			} else {
+				assert.True(t, os.IsNotExist(err), "Case %d: %s: %s should not exist", i, tc.name, path)
+			}
+		}
+	}
+}
+
+// newModule returns a temporary directory that contains the module "foo".
+func newModule(t *testing.T) string {
+	wd := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(wd, "go.mod"), []byte("module foo\n"), 0644))
+	return wd
+}
+
+// fakeRunner is a govet.Runner that records the commands that it is asked to run and replays recorded output for them.
+type fakeRunner struct {
+	// wd replaces the "$WD" placeholder in the recorded output.
+	wd       string
+	stdout   string
+	stderr   string
+	exitCode int
+	err      error
+	// retry runs the commands after the first one if it is not nil.
+	retry *fakeRunner
+	// onRun is called with every command before its output is replayed if it is not nil.
+	onRun func(cmd govet.Command)
+	cmds  []govet.Command
+}
+
+// loadFixture loads the output that the runner replays from the archive testdata/vet/<name>.txtar, which contains the
+// files "exit", "stdout" and "stderr".
+func (r *fakeRunner) loadFixture(t *testing.T, name string) {
+	archive, err := txtar.ParseFile(filepath.Join("testdata", "vet", name+".txtar"))
+	require.NoError(t, err)
+	for _, file := range archive.Files {
+		content := strings.ReplaceAll(string(file.Data), "$WD", r.wd)
+		switch file.Name {
+		case "exit":
+			r.exitCode, err = strconv.Atoi(strings.TrimSpace(content))
+			require.NoError(t, err)
+		case "stdout":
+			r.stdout = content
+		case "stderr":
+			r.stderr = content
+		default:
+			require.Failf(t, "unexpected file in fixture", "%s: %s", name, file.Name)
+		}
+	}
+}
+
+func (r *fakeRunner) Run(ctx context.Context, cmd govet.Command, stdout, stderr io.Writer) (int, error) {
+	r.cmds = append(r.cmds, cmd)
+	if r.onRun != nil {
+		r.onRun(cmd)
+	}
+	if r.retry != nil && len(r.cmds) > 1 {
+		return r.retry.Run(ctx, cmd, stdout, stderr)
+	}
+	if r.err != nil {
+		return -1, r.err
+	}
+	if _, err := io.WriteString(stdout, r.stdout); err != nil {
+		return -1, err
+	}
+	if _, err := io.WriteString(stderr, r.stderr); err != nil {
+		return -1, err
+	}
+	return r.exitCode, nil
+}
@@ -0,0 +1,295 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package govettest tests custom analyzers the way that an asset built with them runs them: "go vet" runs the test
+// binary as its vet tool, and the diagnostics that the asset reports are compared to the expectations in the comments
+// of the test data, like the expectations of the analysistest package.
+//
+// The test binary must call Main from its TestMain function so that it can act as the vet tool:
+//
+//	func TestMain(m *testing.M) {
+//		govettest.Main(m, myanalyzer.Analyzer)
+//	}
+//
+//	func TestMyAnalyzer(t *testing.T) {
+//		govettest.Run(t, "testdata", []*analysis.Analyzer{myanalyzer.Analyzer}, "./...")
+//	}
+package govettest
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"go/parser"
+	"go/scanner"
+	"go/token"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+)
+
+// Testing is the subset of *testing.T that is used to report the results of Run.
+type Testing interface {
+	Errorf(format string, args ...any)
+	Fatalf(format string, args ...any)
+	Helper()
+}
+
+// Main registers the provided analyzers and runs the tests, unless the test binary was invoked by "go vet" as its vet
+// tool, in which case it runs as the vet tool. Must be called from the TestMain function of the package that uses Run.
+// Does not return.
+func Main(m *testing.M, analyzerList ...*analysis.Analyzer) {
+	if err := govet.RegisterAnalyzers(analyzerList...); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	if govet.IsVetToolInvocation() {
+		govet.VetToolMain()
+	}
+	os.Exit(m.Run())
+}
+
+// Run vets the packages that match the provided patterns in the module in dir with only the provided analyzers, which
+// must have been registered using Main, and returns the diagnostics. The diagnostics are compared to the expectations
+// in the comments of the files of the vetted packages, including their test files: a comment of the form
+//
+//	// want "regexp" `regexp`...
+//
+// expects a diagnostic on its line for every regular expression, whose message matches the expression. An error is
+// reported for every diagnostic that is not expected and for every expectation that is not met. Fails the test if the
+// packages could not be vetted, such as if they have type errors.
+func Run(t Testing, dir string, analyzerList []*analysis.Analyzer, patterns ...string) []govet.Diagnostic {
+	t.Helper()
+
+	absDir, err := filepath.Abs(dir)
+	if err != nil {
+		t.Fatalf("failed to resolve directory %s: %v", dir, err)
+		return nil
+	}
+	files, err := packageFiles(absDir, patterns)
+	if err != nil {
+		t.Fatalf("%v", err)
+		return nil
+	}
+	expectations, err := loadExpectations(files)
+	if err != nil {
+		t.Fatalf("%v", err)
+		return nil
+	}
+	vetTool, err := os.Executable()
+	if err != nil {
+		t.Fatalf("failed to determine test executable: %v", err)
+		return nil
+	}
+	enable, disable, err := analyzerNames(analyzerList)
+	if err != nil {
+		t.Fatalf("%v", err)
+		return nil
+	}
+	diags, err := govet.Run(context.Background(), govet.Options{
+		Packages:         patterns,
+		Dir:              absDir,
+		EnableAnalyzers:  enable,
+		DisableAnalyzers: disable,
+		VetTool:          vetTool,
+	})
+	if err != nil {
+		t.Fatalf("failed to vet packages: %v", err)
+		return nil
+	}
+
+	for _, diag := range diags {
+		if !expectations.match(diag) {
+			t.Errorf("%s: unexpected diagnostic: %s", relativePosition(diag.Pos, absDir), diag.Message)
+		}
+	}
+	for _, exp := range expectations {
+		if !exp.met {
+			t.Errorf("%s:%d: no diagnostic was reported matching %#q", relativeFilename(exp.filename, absDir), exp.line, exp.rx)
+		}
+	}
+	return diags
+}
+
+// analyzerNames returns the names of the provided analyzers, which must be available, and the names of the other
+// analyzers that are enabled by default.
+func analyzerNames(analyzerList []*analysis.Analyzer) (enable, disable []string, rErr error) {
+	infos, err := (&govet.Checker{}).Analyzers()
+	if err != nil {
+		return nil, nil, err
+	}
+	for _, analyzer := range analyzerList {
+		if !slices.ContainsFunc(infos, func(info govet.AnalyzerInfo) bool {
+			return info.Name == analyzer.Name
+		}) {
+			return nil, nil, errors.Errorf("analyzer %q is not registered: it must be provided to govettest.Main", analyzer.Name)
+		}
+		enable = append(enable, analyzer.Name)
+	}
+	for _, info := range infos {
+		if info.Default && !slices.Contains(enable, info.Name) {
+			disable = append(disable, info.Name)
+		}
+	}
+	return enable, disable, nil
+}
+
+// expectation is a diagnostic that is expected by a "want" comment.
+type expectation struct {
+	filename string
+	line     int
+	rx       *regexp.Regexp
+	met      bool
+}
+
+type expectations []*expectation
+
+// match marks the first expectation that is not yet met and matches the provided diagnostic as met. Returns false if
+// there is no such expectation.
+func (e expectations) match(diag govet.Diagnostic) bool {
+	for _, exp := range e {
+		if !exp.met && exp.filename == diag.Pos.Filename && exp.line == diag.Pos.Line && exp.rx.MatchString(diag.Message) {
+			exp.met = true
+			return true
+		}
+	}
+	return false
+}
+
+// packageFiles returns the absolute paths of the Go files of the packages that match the provided patterns in the
+// module in dir, including their test files.
+func packageFiles(dir string, patterns []string) ([]string, error) {
+	cmd := exec.Command("go", append([]string{"list", "-json"}, patterns...)...)
+	cmd.Dir = dir
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+	output, err := cmd.Output()
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list packages: %s", strings.TrimSpace(stderr.String()))
+	}
+	var files []string
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for decoder.More() {
+		var pkg struct {
+			Dir          string
+			GoFiles      []string
+			CgoFiles     []string
+			TestGoFiles  []string
+			XTestGoFiles []string
+		}
+		if err := decoder.Decode(&pkg); err != nil {
+			return nil, errors.Wrapf(err, "failed to parse output of go list")
+		}
+		for _, names := range [][]string{pkg.GoFiles, pkg.CgoFiles, pkg.TestGoFiles, pkg.XTestGoFiles} {
+			for _, name := range names {
+				files = append(files, filepath.Join(pkg.Dir, name))
+			}
+		}
+	}
+	return files, nil
+}
+
+// loadExpectations returns the expectations of the "want" comments in the provided Go files.
+func loadExpectations(files []string) (expectations, error) {
+	var exps expectations
+	fset := token.NewFileSet()
+	for _, path := range files {
+		file, err := parser.ParseFile(fset, path, nil, parser.ParseComments)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to parse %s", path)
+		}
+		for _, group := range file.Comments {
+			for _, comment := range group.List {
+				text := strings.TrimSpace(strings.TrimSuffix(strings.TrimPrefix(strings.TrimPrefix(comment.Text, "//"), "/*"), "*/"))
+				rest, ok := strings.CutPrefix(text, "want ")
+				if !ok {
+					continue
+				}
+				pos := fset.Position(comment.Pos())
+				rxs, err := parseWant(rest)
+				if err != nil {
+					return nil, errors.Wrapf(err, "%s: invalid want comment", pos)
+				}
+				for _, rx := range rxs {
+					exps = append(exps, &expectation{
+						filename: path,
+						line:     pos.Line,
+						rx:       rx,
+					})
+				}
+			}
+		}
+	}
+	return exps, nil
+}
+
+// parseWant parses the regular expressions of a "want" comment, which are Go string literals separated by spaces.
+func parseWant(text string) ([]*regexp.Regexp, error) {
+	var (
+		s    scanner.Scanner
+		errs scanner.ErrorList
+		rxs  []*regexp.Regexp
+	)
+	fset := token.NewFileSet()
+	s.Init(fset.AddFile("", -1, len(text)), []byte(text), func(pos token.Position, msg string) {
+		errs.Add(pos, msg)
+	}, 0)
+	for {
+		_, tok, lit := s.Scan()
+		switch tok {
+		case token.EOF:
+			if len(rxs) == 0 {
+				return nil, errors.New("no regular expressions")
+			}
+			return rxs, errs.Err()
+		case token.SEMICOLON:
+			// the scanner inserts a semicolon after the last literal
+			continue
+		case token.STRING:
+			unquoted, err := strconv.Unquote(lit)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid string literal %s", lit)
+			}
+			rx, err := regexp.Compile(unquoted)
+			if err != nil {
+				return nil, errors.Wrapf(err, "invalid regular expression %q", unquoted)
+			}
+			rxs = append(rxs, rx)
+		default:
+			return nil, errors.Errorf("expected string literal, got %s", tok)
+		}
+	}
+}
+
+func relativePosition(pos govet.Position, dir string) string {
+	pos.Filename = relativeFilename(pos.Filename, dir)
+	return pos.String()
+}
+
+func relativeFilename(filename, dir string) string {
+	if rel, err := filepath.Rel(dir, filename); err == nil && !strings.HasPrefix(rel, "..") {
+		return rel
+	}
+	return filename
+}
@@ -0,0 +1,84 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govettest_test
+
+import (
+	"fmt"
+	"go/ast"
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/govettest"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/go/analysis"
+)
+
+// callsAnalyzer reports calls of functions named "bad".
+var callsAnalyzer = &analysis.Analyzer{
+	Name: "nobadcalls",
+	Doc:  "nobadcalls: report calls of functions named bad",
+	Run: func(pass *analysis.Pass) (any, error) {
+		for _, file := range pass.Files {
+			ast.Inspect(file, func(n ast.Node) bool {
+				if call, ok := n.(*ast.CallExpr); ok {
+					if ident, ok := call.Fun.(*ast.Ident); ok && ident.Name == "bad" {
+						pass.Reportf(call.Pos(), "call of bad")
+					}
+				}
+				return true
+			})
+		}
+		return nil, nil
+	},
+}
+
+func TestMain(m *testing.M) {
+	govettest.Main(m, callsAnalyzer)
+}
+
+func TestRun(t *testing.T) {
+	diags := govettest.Run(t, "testdata", []*analysis.Analyzer{callsAnalyzer}, "./calls")
+	assert.Len(t, diags, 3)
+}
+
+func TestRunMismatch(t *testing.T) {
+	rec := &recorder{}
+	govettest.Run(rec, "testdata", []*analysis.Analyzer{callsAnalyzer}, "./mismatch")
+	assert.Equal(t, []string{
+		"mismatch/mismatch.go:6:2: unexpected diagnostic: call of bad",
+		"mismatch/mismatch.go:7: no diagnostic was reported matching `call of bad`",
+	}, rec.errors)
+	assert.Empty(t, rec.fatals)
+}
+
+func TestRunUnregisteredAnalyzer(t *testing.T) {
+	rec := &recorder{}
+	govettest.Run(rec, "testdata", []*analysis.Analyzer{{Name: "unregistered"}}, "./calls")
+	assert.Equal(t, []string{`analyzer "unregistered" is not registered: it must be provided to govettest.Main`}, rec.fatals)
+}
+
+type recorder struct {
+	errors []string
+	fatals []string
+}
+
+func (r *recorder) Errorf(format string, args ...any) {
+	r.errors = append(r.errors, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Fatalf(format string, args ...any) {
+	r.fatals = append(r.fatals, fmt.Sprintf(format, args...))
+}
+
+func (r *recorder) Helper() {}
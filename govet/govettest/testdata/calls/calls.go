@@ -0,0 +1,11 @@
+package calls
+
+func bad() int { return 0 }
+
+func good() int { return 0 }
+
+func f() {
+	bad() // want "call of bad"
+	good()
+	_, _ = bad(), bad() /* want `call of bad` "call of bad" */
+}
@@ -0,0 +1,8 @@
+package mismatch
+
+func bad() int { return 0 }
+
+func f() {
+	bad()
+	_ = 1 // want "call of bad"
+}
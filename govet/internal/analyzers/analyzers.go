@@ -0,0 +1,163 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package analyzers provides information about the analyzers that are run by "go vet".
+package analyzers
+
+import (
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/nilness"
+	"golang.org/x/tools/go/analysis/passes/shadow"
+	"golang.org/x/tools/go/analysis/passes/unusedwrite"
+	"golang.org/x/tools/go/analysis/suite/vet"
+)
+
+var (
+	registeredMu sync.RWMutex
+	// registered are the analyzers that were registered in addition to the bundled analyzers, in the order in which
+	// they were registered.
+	registered []*analysis.Analyzer
+	// optional are the registered analyzers that are disabled by default.
+	optional = make(map[*analysis.Analyzer]bool)
+)
+
+// Vet returns the analyzers in the "go vet" suite. These analyzers are enabled by default.
+func Vet() []*analysis.Analyzer {
+	return vet.Suite
+}
+
+// Extras returns the analyzers that are bundled with the asset in addition to the "go vet" suite. These analyzers
+// are disabled by default because they report findings that are not necessarily problems.
+func Extras() []*analysis.Analyzer {
+	return []*analysis.Analyzer{
+		nilness.Analyzer,
+		shadow.Analyzer,
+		unusedwrite.Analyzer,
+	}
+}
+
+// Registered returns the analyzers that were registered using Register in the order in which they were registered.
+func Registered() []*analysis.Analyzer {
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+	return slices.Clone(registered)
+}
+
+// Register adds the provided analyzers to the available analyzers. Registered analyzers are enabled by default unless
+// isOptional is true. Registering an analyzer that is already registered has no effect. Returns an error if an analyzer
+// is not valid or if its name is the name of another available analyzer, in which case none of the analyzers are
+// registered.
+func Register(analyzerList []*analysis.Analyzer, isOptional bool) error {
+	if err := analysis.Validate(analyzerList); err != nil {
+		return errors.Wrapf(err, "invalid analyzer")
+	}
+	registeredMu.Lock()
+	defer registeredMu.Unlock()
+
+	available := append(append(slices.Clone(Vet()), Extras()...), registered...)
+	var added []*analysis.Analyzer
+	for _, analyzer := range analyzerList {
+		if slices.Contains(available, analyzer) {
+			continue
+		}
+		if slices.ContainsFunc(available, func(other *analysis.Analyzer) bool {
+			return other.Name == analyzer.Name
+		}) {
+			return errors.Errorf("cannot register analyzer %q: another analyzer with the same name is available", analyzer.Name)
+		}
+		available = append(available, analyzer)
+		added = append(added, analyzer)
+	}
+	registered = append(registered, added...)
+	for _, analyzer := range added {
+		optional[analyzer] = isOptional
+	}
+	return nil
+}
+
+// All returns all of the analyzers that are available: the "go vet" suite followed by the extras and the registered
+// analyzers.
+func All() []*analysis.Analyzer {
+	return append(append(slices.Clone(Vet()), Extras()...), Registered()...)
+}
+
+// IsDefault returns true if the provided analyzer is enabled by default: the analyzers in the "go vet" suite and the
+// registered analyzers that are not optional.
+func IsDefault(analyzer *analysis.Analyzer) bool {
+	if slices.Contains(Vet(), analyzer) {
+		return true
+	}
+	registeredMu.RLock()
+	defer registeredMu.RUnlock()
+	return slices.Contains(registered, analyzer) && !optional[analyzer]
+}
+
+// Select returns the analyzers in available that are enabled when the analyzers named in enable are enabled and the
+// analyzers named in disable are disabled. Analyzers that are not named in either are enabled if they are enabled by
+// default. Returns an error if enable or disable names an analyzer that is not available or if an analyzer is named
+// in both.
+func Select(available []*analysis.Analyzer, enable, disable []string) ([]*analysis.Analyzer, error) {
+	if err := Validate(available, enable, disable); err != nil {
+		return nil, err
+	}
+	var selected []*analysis.Analyzer
+	for _, analyzer := range available {
+		if slices.Contains(disable, analyzer.Name) {
+			continue
+		}
+		if IsDefault(analyzer) || slices.Contains(enable, analyzer.Name) {
+			selected = append(selected, analyzer)
+		}
+	}
+	return selected, nil
+}
+
+// Validate returns an error if enable or disable names an analyzer that is not in available or if an analyzer is
+// named in both.
+func Validate(available []*analysis.Analyzer, enable, disable []string) error {
+	for _, names := range []struct {
+		field string
+		names []string
+	}{
+		{"enable", enable},
+		{"disable", disable},
+	} {
+		for _, name := range names.names {
+			if !slices.ContainsFunc(available, func(analyzer *analysis.Analyzer) bool {
+				return analyzer.Name == name
+			}) {
+				return errors.Errorf("unknown analyzer %q in %s list", name, names.field)
+			}
+		}
+	}
+	for _, name := range enable {
+		if slices.Contains(disable, name) {
+			return errors.Errorf("analyzer %q is both enabled and disabled", name)
+		}
+	}
+	return nil
+}
+
+// Title returns the title of the provided analyzer, which is the first paragraph of its documentation. The analyzer
+// name is removed if the documentation starts with it.
+func Title(analyzer *analysis.Analyzer) string {
+	title, _, _ := strings.Cut(analyzer.Doc, "\n\n")
+	title = strings.TrimPrefix(title, analyzer.Name+": ")
+	return strings.Join(strings.Fields(title), " ")
+}
@@ -0,0 +1,213 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyzers_test
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/analyzers"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/analysis"
+)
+
+func TestSelect(t *testing.T) {
+	for i, tc := range []struct {
+		name      string
+		enable    []string
+		disable   []string
+		want      []string
+		wantError string
+	}{
+		{
+			name: "defaults",
+			want: names(analyzers.Vet()),
+		},
+		{
+			name:    "extra enabled and default disabled",
+			enable:  []string{"shadow"},
+			disable: []string{"printf"},
+			want:    append(without(names(analyzers.Vet()), "printf"), "shadow"),
+		},
+		{
+			name:      "unknown analyzer",
+			enable:    []string{"unknown"},
+			wantError: `unknown analyzer "unknown" in enable list`,
+		},
+		{
+			name:      "analyzer enabled and disabled",
+			enable:    []string{"shadow"},
+			disable:   []string{"shadow"},
+			wantError: `analyzer "shadow" is both enabled and disabled`,
+		},
+	} {
+		got, err := analyzers.Select(analyzers.All(), tc.enable, tc.disable)
+		if tc.wantError != "" {
+			assert.EqualError(t, err, tc.wantError, "Case %d: %s", i, tc.name)
+			continue
+		}
+		require.NoError(t, err, "Case %d: %s", i, tc.name)
+		assert.Equal(t, tc.want, names(got), "Case %d: %s", i, tc.name)
+	}
+}
+
+func TestTitle(t *testing.T) {
+	assert.Equal(t, "check for useless assignments", analyzers.Title(&analysis.Analyzer{
+		Name: "assign",
+		Doc:  "assign: check for useless\nassignments\n\nLonger description.",
+	}))
+}
+
+func names(analyzerList []*analysis.Analyzer) []string {
+	var out []string
+	for _, analyzer := range analyzerList {
+		out = append(out, analyzer.Name)
+	}
+	return out
+}
+
+func without(in []string, excluded ...string) []string {
+	var out []string
+	for _, s := range in {
+		if !slices.Contains(excluded, s) {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func TestExampleFor(t *testing.T) {
+	for _, analyzer := range append(analyzers.Vet(), analyzers.Extras()...) {
+		example, ok := analyzers.ExampleFor(analyzer.Name)
+		if assert.True(t, ok, "no example for analyzer %s", analyzer.Name) {
+			assert.NotEqual(t, example.Bad, example.Good, "example for analyzer %s", analyzer.Name)
+		}
+	}
+	_, ok := analyzers.ExampleFor("unknown")
+	assert.False(t, ok)
+}
+
+func TestForGoVersion(t *testing.T) {
+	loopclosureReason := "loop variables are scoped per iteration since go1.22"
+	stdversionReason := "the go directive is only a minimum requirement since go1.21"
+	waitgroupReason := "the analyzer is part of go vet since go1.25"
+	for i, tc := range []struct {
+		name            string
+		selected        []string
+		goVersion       string
+		explicit        []string
+		want            []string
+		wantAdjustments []analyzers.Adjustment
+	}{
+		{
+			name:     "no version",
+			selected: names(analyzers.Vet()),
+			want:     names(analyzers.Vet()),
+		},
+		{
+			name:      "old version",
+			selected:  names(analyzers.Vet()),
+			goVersion: "go1.20",
+			want:      without(names(analyzers.Vet()), "stdversion", "waitgroup"),
+			wantAdjustments: []analyzers.Adjustment{
+				{Analyzer: "stdversion", Reason: stdversionReason},
+				{Analyzer: "waitgroup", Reason: waitgroupReason},
+			},
+		},
+		{
+			name:      "recent version",
+			selected:  names(analyzers.Vet()),
+			goVersion: "go1.25.1",
+			want:      without(names(analyzers.Vet()), "loopclosure"),
+			wantAdjustments: []analyzers.Adjustment{
+				{Analyzer: "loopclosure", Reason: loopclosureReason},
+			},
+		},
+		{
+			name:      "explicitly selected analyzers are not adjusted",
+			selected:  names(analyzers.Vet()),
+			goVersion: "go1.25",
+			explicit:  []string{"loopclosure"},
+			want:      names(analyzers.Vet()),
+		},
+		{
+			name:      "analyzer that is not selected is enabled",
+			selected:  without(names(analyzers.Vet()), "loopclosure"),
+			goVersion: "go1.21",
+			want:      without(names(analyzers.Vet()), "waitgroup"),
+			wantAdjustments: []analyzers.Adjustment{
+				{Analyzer: "loopclosure", Enabled: true, Reason: loopclosureReason},
+				{Analyzer: "waitgroup", Reason: waitgroupReason},
+			},
+		},
+	} {
+		var selected []*analysis.Analyzer
+		for _, analyzer := range analyzers.All() {
+			if slices.Contains(tc.selected, analyzer.Name) {
+				selected = append(selected, analyzer)
+			}
+		}
+		got, adjustments := analyzers.ForGoVersion(analyzers.All(), selected, tc.goVersion, tc.explicit)
+		assert.Equal(t, tc.want, names(got), "Case %d: %s", i, tc.name)
+		assert.Equal(t, tc.wantAdjustments, adjustments, "Case %d: %s", i, tc.name)
+	}
+}
+
+func TestUnsupported(t *testing.T) {
+	assert.Equal(t, "loop variables are scoped per iteration since go1.22", analyzers.Unsupported("loopclosure", "go1.22"))
+	assert.Equal(t, "", analyzers.Unsupported("loopclosure", "go1.21.5"))
+	assert.Equal(t, "the analyzer is part of go vet since go1.25", analyzers.Unsupported("waitgroup", "go1.24"))
+	assert.Equal(t, "", analyzers.Unsupported("waitgroup", ""))
+	assert.Equal(t, "", analyzers.Unsupported("printf", "go1.18"))
+}
+
+func TestGoVersion(t *testing.T) {
+	assert.Equal(t, "go1.21", analyzers.GoVersion("1.21"))
+	assert.Equal(t, "go1.22.3", analyzers.GoVersion("1.22.3"))
+	assert.Equal(t, "", analyzers.GoVersion(""))
+	assert.Equal(t, "", analyzers.GoVersion("invalid"))
+}
+
+func TestRegister(t *testing.T) {
+	newAnalyzer := func(name string) *analysis.Analyzer {
+		return &analysis.Analyzer{
+			Name: name,
+			Doc:  name + ": test analyzer",
+			Run: func(pass *analysis.Pass) (any, error) {
+				return nil, nil
+			},
+		}
+	}
+	custom := newAnalyzer("custom")
+	optional := newAnalyzer("customoptional")
+
+	require.NoError(t, analyzers.Register([]*analysis.Analyzer{custom}, false))
+	require.NoError(t, analyzers.Register([]*analysis.Analyzer{optional, custom}, true))
+	assert.Equal(t, []*analysis.Analyzer{custom, optional}, analyzers.Registered())
+	assert.Equal(t, []string{"custom", "customoptional"}, names(analyzers.All())[len(analyzers.All())-2:])
+	assert.True(t, analyzers.IsDefault(custom))
+	assert.False(t, analyzers.IsDefault(optional))
+
+	selected, err := analyzers.Select(analyzers.All(), nil, []string{"printf"})
+	require.NoError(t, err)
+	assert.Equal(t, append(without(names(analyzers.Vet()), "printf"), "custom"), names(selected))
+
+	err = analyzers.Register([]*analysis.Analyzer{newAnalyzer("other"), newAnalyzer("printf")}, false)
+	assert.EqualError(t, err, `cannot register analyzer "printf": another analyzer with the same name is available`)
+	err = analyzers.Register([]*analysis.Analyzer{{Name: "invalid-name", Doc: "doc", Run: custom.Run}}, false)
+	assert.EqualError(t, err, `invalid analyzer: invalid analyzer name "invalid-name"`)
+	assert.Equal(t, []*analysis.Analyzer{custom, optional}, analyzers.Registered())
+}
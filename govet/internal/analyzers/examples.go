@@ -0,0 +1,54 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyzers
+
+import (
+	"embed"
+	"strings"
+
+	"golang.org/x/tools/txtar"
+)
+
+// examplesFS contains an example for every available analyzer. The example for an analyzer is a txtar archive named
+// "<analyzer>.txtar" that contains the files "bad.go", which the analyzer reports, and "good.go", which fixes the
+// finding.
+//
+//go:embed examples/*.txtar
+var examplesFS embed.FS
+
+// Example is an example of code that an analyzer reports along with the code that fixes the finding.
+type Example struct {
+	Bad  string
+	Good string
+}
+
+// ExampleFor returns the example for the analyzer with the provided name. Returns false if there is no example for
+// the analyzer.
+func ExampleFor(name string) (Example, bool) {
+	content, err := examplesFS.ReadFile("examples/" + name + ".txtar")
+	if err != nil {
+		return Example{}, false
+	}
+	var example Example
+	for _, file := range txtar.Parse(content).Files {
+		switch file.Name {
+		case "bad.go":
+			example.Bad = strings.TrimSuffix(string(file.Data), "\n")
+		case "good.go":
+			example.Good = strings.TrimSuffix(string(file.Data), "\n")
+		}
+	}
+	return example, example.Bad != "" && example.Good != ""
+}
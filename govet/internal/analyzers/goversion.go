@@ -0,0 +1,132 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyzers
+
+import (
+	"go/version"
+	"slices"
+	"strings"
+
+	"golang.org/x/tools/go/analysis"
+)
+
+// versionRule restricts an analyzer to the modules whose go directive declares a version in a range of versions.
+type versionRule struct {
+	analyzer string
+	// since is the first version for which the analyzer is run. No lower bound if empty.
+	since string
+	// before is the first version for which the analyzer is no longer run. No upper bound if empty.
+	before string
+	reason string
+}
+
+var versionRules = []versionRule{
+	{
+		analyzer: "loopclosure",
+		before:   "go1.22",
+		reason:   "loop variables are scoped per iteration since go1.22",
+	},
+	{
+		analyzer: "stdversion",
+		since:    "go1.21",
+		reason:   "the go directive is only a minimum requirement since go1.21",
+	},
+	{
+		analyzer: "waitgroup",
+		since:    "go1.25",
+		reason:   "the analyzer is part of go vet since go1.25",
+	},
+}
+
+// includes returns true if the analyzer of the rule is run for a module whose go directive declares the provided
+// version.
+func (r versionRule) includes(goVersion string) bool {
+	return (r.since == "" || version.Compare(goVersion, r.since) >= 0) &&
+		(r.before == "" || version.Compare(goVersion, r.before) < 0)
+}
+
+// Adjustment is a change to the analyzers that are run for a module that is made because of the Go version declared by
+// its go directive.
+type Adjustment struct {
+	Analyzer string
+	// Enabled is true if the analyzer is run even though it was not selected, and false if the analyzer is not run
+	// even though it was selected.
+	Enabled bool
+	Reason  string
+}
+
+// GoVersion returns the Go version for the version in a go directive, such as "go1.21" for "1.21". Returns the empty
+// string if the provided version is not valid.
+func GoVersion(goDirective string) string {
+	goVersion := "go" + strings.TrimPrefix(goDirective, "go")
+	if !version.IsValid(goVersion) {
+		return ""
+	}
+	return goVersion
+}
+
+// ForGoVersion returns the analyzers in available that are run for a module whose go directive declares the provided
+// version when the provided analyzers are selected, along with the adjustments that were made to the selection.
+// Analyzers with a version rule are run only if the version is in the range of the rule. Analyzers named in explicit
+// are never adjusted, and no adjustments are made if goVersion is empty.
+func ForGoVersion(available, selected []*analysis.Analyzer, goVersion string, explicit []string) ([]*analysis.Analyzer, []Adjustment) {
+	if goVersion == "" {
+		return selected, nil
+	}
+	var (
+		adjusted    []*analysis.Analyzer
+		adjustments []Adjustment
+	)
+	for _, analyzer := range available {
+		isSelected := slices.Contains(selected, analyzer)
+		ruleIdx := slices.IndexFunc(versionRules, func(rule versionRule) bool {
+			return rule.analyzer == analyzer.Name
+		})
+		if ruleIdx == -1 || slices.Contains(explicit, analyzer.Name) {
+			if isSelected {
+				adjusted = append(adjusted, analyzer)
+			}
+			continue
+		}
+		rule := versionRules[ruleIdx]
+		inRange := rule.includes(goVersion)
+		if inRange {
+			adjusted = append(adjusted, analyzer)
+		}
+		if inRange != isSelected {
+			adjustments = append(adjustments, Adjustment{
+				Analyzer: analyzer.Name,
+				Enabled:  inRange,
+				Reason:   rule.reason,
+			})
+		}
+	}
+	return adjusted, adjustments
+}
+
+// Unsupported returns the reason why the analyzer with the provided name is not run for a module whose go directive
+// declares the provided version. Returns the empty string if the analyzer is run for the version or if goVersion is
+// empty.
+func Unsupported(name, goVersion string) string {
+	if goVersion == "" {
+		return ""
+	}
+	for _, rule := range versionRules {
+		if rule.analyzer == name && !rule.includes(goVersion) {
+			return rule.reason
+		}
+	}
+	return ""
+}
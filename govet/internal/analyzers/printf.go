@@ -0,0 +1,97 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyzers
+
+import (
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis/passes/printf"
+)
+
+// PrintfFuncsFlagName is the name of the flag of the printf analyzer that adds functions to the functions that it
+// checks.
+const PrintfFuncsFlagName = "funcs"
+
+// printfWrappers maps the qualifiers of well-known printf wrappers to the names of the wrappers. A qualifier is either
+// the path of the package of a function or the receiver of a method in the form used by (*types.Func).FullName, such
+// as "(*github.com/sirupsen/logrus.Entry)". The printf analyzer infers that a function is a wrapper if it forwards its
+// arguments to a known printf function, but it cannot infer it for wrappers that format messages in other ways, such
+// as the testify assertions, or for wrappers in packages that are not analyzed.
+var printfWrappers = map[string][]string{
+	"github.com/pkg/errors": {"Errorf", "WithMessagef", "Wrapf"},
+
+	"github.com/sirupsen/logrus":               logrusFormatFuncs,
+	"(*github.com/sirupsen/logrus.Logger)":     append(logrusFormatFuncs, "Logf"),
+	"(*github.com/sirupsen/logrus.Entry)":      append(logrusFormatFuncs, "Logf"),
+	"(github.com/sirupsen/logrus.FieldLogger)": logrusFormatFuncs,
+
+	"(*go.uber.org/zap.SugaredLogger)": {"Debugf", "Infof", "Warnf", "Errorf", "DPanicf", "Panicf", "Fatalf", "Logf"},
+
+	"github.com/stretchr/testify/assert":                testifyFormatFuncs,
+	"(*github.com/stretchr/testify/assert.Assertions)":  testifyFormatFuncs,
+	"github.com/stretchr/testify/require":               testifyFormatFuncs,
+	"(*github.com/stretchr/testify/require.Assertions)": testifyFormatFuncs,
+}
+
+var logrusFormatFuncs = []string{"Tracef", "Debugf", "Infof", "Printf", "Warnf", "Warningf", "Errorf", "Fatalf", "Panicf"}
+
+// testifyFormatFuncs are the names of the assertions of testify that format their message arguments.
+var testifyFormatFuncs = []string{
+	"Conditionf", "Containsf", "DirExistsf", "ElementsMatchf", "Emptyf", "EqualErrorf", "EqualExportedValuesf",
+	"EqualValuesf", "Equalf", "ErrorAsf", "ErrorContainsf", "ErrorIsf", "Errorf", "EventuallyWithTf", "Eventuallyf",
+	"Exactlyf", "FailNowf", "Failf", "Falsef", "FileExistsf", "GreaterOrEqualf", "Greaterf", "HTTPBodyContainsf",
+	"HTTPBodyNotContainsf", "HTTPErrorf", "HTTPRedirectf", "HTTPStatusCodef", "HTTPSuccessf", "Implementsf",
+	"InDeltaMapValuesf", "InDeltaSlicef", "InDeltaf", "InEpsilonSlicef", "InEpsilonf", "IsDecreasingf",
+	"IsIncreasingf", "IsNonDecreasingf", "IsNonIncreasingf", "IsNotTypef", "IsTypef", "JSONEqf", "Lenf",
+	"LessOrEqualf", "Lessf", "Negativef", "Neverf", "Nilf", "NoDirExistsf", "NoErrorf", "NoFileExistsf",
+	"NotContainsf", "NotElementsMatchf", "NotEmptyf", "NotEqualValuesf", "NotEqualf", "NotErrorAsf", "NotErrorIsf",
+	"NotImplementsf", "NotNilf", "NotPanicsf", "NotRegexpf", "NotSamef", "NotSubsetf", "NotZerof",
+	"PanicsWithErrorf", "PanicsWithValuef", "Panicsf", "Positivef", "Regexpf", "Samef", "Subsetf", "Truef",
+	"WithinDurationf", "WithinRangef", "YAMLEqf", "Zerof",
+}
+
+// PrintfWrappers returns the qualified names of the well-known printf wrappers that the printf analyzer checks in
+// addition to the functions that it knows or infers, in the form accepted by its "funcs" flag.
+func PrintfWrappers() []string {
+	var names []string
+	for qualifier, funcs := range printfWrappers {
+		for _, name := range funcs {
+			names = append(names, qualifier+"."+name)
+		}
+	}
+	return names
+}
+
+// RecognizePrintfWrappers adds the well-known printf wrappers to the functions that the printf analyzer checks. The
+// printf analyzer is modified in place, so RecognizePrintfWrappers must only be called by processes that are dedicated
+// to running the analyzers.
+func RecognizePrintfWrappers() error {
+	if err := printf.Analyzer.Flags.Set(PrintfFuncsFlagName, strings.Join(PrintfWrappers(), ",")); err != nil {
+		return errors.Wrapf(err, "failed to set printf wrappers")
+	}
+	return nil
+}
+
+// ValidatePrintfFuncs returns an error if a provided name cannot be provided to the "funcs" flag of the printf
+// analyzer.
+func ValidatePrintfFuncs(names []string) error {
+	for _, name := range names {
+		if name == "" || strings.ContainsAny(name, ", \t\n") {
+			return errors.Errorf("invalid printf function %q: must be a non-empty function name without commas or whitespace", name)
+		}
+	}
+	return nil
+}
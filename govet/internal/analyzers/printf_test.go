@@ -0,0 +1,55 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package analyzers_test
+
+import (
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/govettest"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/analyzers"
+	"github.com/stretchr/testify/assert"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/printf"
+)
+
+func TestMain(m *testing.M) {
+	govettest.Main(m)
+}
+
+// TestPrintfWrappers vets calls of stubs of the well-known printf wrappers whose bodies do not forward their arguments
+// to fmt, so the printf analyzer only reports them because the vet tool recognizes them as wrappers.
+func TestPrintfWrappers(t *testing.T) {
+	govettest.Run(t, "testdata/printf", []*analysis.Analyzer{printf.Analyzer}, "./wrappers")
+}
+
+func TestValidatePrintfFuncs(t *testing.T) {
+	assert.NoError(t, analyzers.ValidatePrintfFuncs(analyzers.PrintfWrappers()))
+	for i, tc := range []struct {
+		names     []string
+		wantError string
+	}{
+		{[]string{"example.com/log.Infof", "(*example.com/log.Logger).Infof", "Infof"}, ""},
+		{[]string{""}, `invalid printf function "": must be a non-empty function name without commas or whitespace`},
+		{[]string{"Infof,Warnf"}, `invalid printf function "Infof,Warnf": must be a non-empty function name without commas or whitespace`},
+		{[]string{"Infof "}, `invalid printf function "Infof ": must be a non-empty function name without commas or whitespace`},
+	} {
+		err := analyzers.ValidatePrintfFuncs(tc.names)
+		if tc.wantError == "" {
+			assert.NoError(t, err, "Case %d", i)
+			continue
+		}
+		assert.EqualError(t, err, tc.wantError, "Case %d", i)
+	}
+}
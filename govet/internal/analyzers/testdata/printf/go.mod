@@ -0,0 +1,17 @@
+module example.com/printf
+
+go 1.22
+
+require (
+	github.com/pkg/errors v0.0.0
+	github.com/sirupsen/logrus v0.0.0
+	github.com/stretchr/testify v0.0.0
+	go.uber.org/zap v0.0.0
+)
+
+replace (
+	github.com/pkg/errors => ./stubs/errors
+	github.com/sirupsen/logrus => ./stubs/logrus
+	github.com/stretchr/testify => ./stubs/testify
+	go.uber.org/zap => ./stubs/zap
+)
@@ -0,0 +1,7 @@
+// Package errors is a stub of github.com/pkg/errors whose functions do not forward their arguments to fmt, so that
+// the printf analyzer only checks them if they are well-known wrappers.
+package errors
+
+func Wrapf(err error, format string, args ...interface{}) error { return err }
+
+func Errorf(format string, args ...interface{}) error { return nil }
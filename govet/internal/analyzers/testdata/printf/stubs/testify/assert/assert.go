@@ -0,0 +1,14 @@
+// Package assert is a stub of github.com/stretchr/testify/assert.
+package assert
+
+type TestingT interface{}
+
+type Assertions struct{}
+
+func New(t TestingT) *Assertions { return &Assertions{} }
+
+func Equalf(t TestingT, expected, actual interface{}, msg string, args ...interface{}) bool {
+	return true
+}
+
+func (a *Assertions) Truef(value bool, msg string, args ...interface{}) bool { return true }
@@ -0,0 +1,27 @@
+package wrappers
+
+import (
+	"github.com/pkg/errors"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"go.uber.org/zap"
+)
+
+func f(err error, entry *logrus.Entry, logger logrus.FieldLogger, sugar *zap.SugaredLogger, t assert.TestingT) {
+	_ = errors.Wrapf(err, "failed to read %d", "s") // want `Wrapf format %d has arg "s" of wrong type string`
+	_ = errors.Wrapf(err, "failed to read %s", "s")
+	_ = errors.Errorf("failed: %w", err) // want `Errorf does not support error-wrapping directive %w`
+
+	entry.Infof("%s") // want `Infof format %s reads arg #1, but call has 0 args`
+	entry.Info("done")
+	logger.Infof("%d", "s") // want `Infof format %d has arg "s" of wrong type string`
+	logrus.Warnf("%s", 1)   // want `Warnf format %s has arg 1 of wrong type int`
+
+	sugar.Errorf("%d", "s") // want `Errorf format %d has arg "s" of wrong type string`
+	sugar.Errorw("%d", "key", "s")
+
+	assert.Equalf(t, 1, 1, "%d", "s")    // want `Equalf format %d has arg "s" of wrong type string`
+	assert.New(t).Truef(true, "%d", "s") // want `Truef format %d has arg "s" of wrong type string`
+	require.NoErrorf(t, err, "%d", "s")  // want `NoErrorf format %d has arg "s" of wrong type string`
+}
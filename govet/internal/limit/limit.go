@@ -0,0 +1,129 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package limit caps the number of findings that are written and summarizes the findings that are omitted.
+package limit
+
+import (
+	"bytes"
+	"fmt"
+	"sort"
+	"sync"
+	"text/tabwriter"
+)
+
+// Limiter decides whether findings are written based on the number of findings that were written before them. It is
+// safe for concurrent use.
+type Limiter struct {
+	// Max is the maximum number of findings that are written. Unlimited if not positive.
+	Max int
+	// MaxPerAnalyzer is the maximum number of findings of a single analyzer that are written. Unlimited if not
+	// positive.
+	MaxPerAnalyzer int
+
+	mu          sync.Mutex
+	written     int
+	perAnalyzer map[string]int
+	// omitted maps analyzers and packages to the number of findings that were omitted
+	omitted map[omittedKey]int
+}
+
+type omittedKey struct {
+	analyzer string
+	pkg      string
+}
+
+// Allow returns true if a finding of the provided analyzer in the provided package should be written, in which case it
+// counts towards the limits. Otherwise, the finding is recorded as omitted.
+func (l *Limiter) Allow(analyzer, pkg string) bool {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if (l.Max > 0 && l.written >= l.Max) || (l.MaxPerAnalyzer > 0 && l.perAnalyzer[analyzer] >= l.MaxPerAnalyzer) {
+		if l.omitted == nil {
+			l.omitted = make(map[omittedKey]int)
+		}
+		l.omitted[omittedKey{analyzer: analyzer, pkg: pkg}]++
+		return false
+	}
+	if l.perAnalyzer == nil {
+		l.perAnalyzer = make(map[string]int)
+	}
+	l.written++
+	l.perAnalyzer[analyzer]++
+	return true
+}
+
+// Summary returns a summary of the omitted findings with their counts grouped by analyzer and package, ordered by
+// descending count. Returns the empty string if no findings were omitted.
+func (l *Limiter) Summary() string {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if len(l.omitted) == 0 {
+		return ""
+	}
+	keys := make([]omittedKey, 0, len(l.omitted))
+	total := 0
+	for key, count := range l.omitted {
+		keys = append(keys, key)
+		total += count
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if ci, cj := l.omitted[keys[i]], l.omitted[keys[j]]; ci != cj {
+			return ci > cj
+		}
+		if keys[i].analyzer != keys[j].analyzer {
+			return keys[i].analyzer < keys[j].analyzer
+		}
+		return keys[i].pkg < keys[j].pkg
+	})
+
+	buf := &bytes.Buffer{}
+	_, _ = fmt.Fprintf(buf, "govet: omitted %d of %d findings because %s:\n", total, total+l.written, l.reason())
+	tw := tabwriter.NewWriter(buf, 0, 0, 2, ' ', 0)
+	_, _ = fmt.Fprintln(tw, "\tANALYZER\tPACKAGE\tOMITTED")
+	for _, key := range keys {
+		_, _ = fmt.Fprintf(tw, "\t%s\t%s\t%d\n", key.analyzer, key.pkg, l.omitted[key])
+	}
+	_ = tw.Flush()
+	return string(bytes.TrimSuffix(buf.Bytes(), []byte("\n")))
+}
+
+// reason returns a description of the limits that were reached.
+func (l *Limiter) reason() string {
+	reachedMax := l.Max > 0 && l.written >= l.Max
+	reachedPerAnalyzer := false
+	if l.MaxPerAnalyzer > 0 {
+		for _, count := range l.perAnalyzer {
+			if count >= l.MaxPerAnalyzer {
+				reachedPerAnalyzer = true
+				break
+			}
+		}
+	}
+	switch {
+	case reachedMax && reachedPerAnalyzer:
+		return fmt.Sprintf("the limits of %s and %s per analyzer were reached", findings(l.Max), findings(l.MaxPerAnalyzer))
+	case reachedMax:
+		return fmt.Sprintf("the limit of %s was reached", findings(l.Max))
+	default:
+		return fmt.Sprintf("the limit of %s per analyzer was reached", findings(l.MaxPerAnalyzer))
+	}
+}
+
+func findings(n int) string {
+	if n == 1 {
+		return "1 finding"
+	}
+	return fmt.Sprintf("%d findings", n)
+}
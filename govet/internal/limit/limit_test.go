@@ -0,0 +1,90 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package limit_test
+
+import (
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/limit"
+	"github.com/stretchr/testify/assert"
+)
+
+type finding struct {
+	analyzer string
+	pkg      string
+}
+
+func TestLimiter(t *testing.T) {
+	findings := []finding{
+		{"printf", "foo"},
+		{"printf", "foo"},
+		{"printf", "foo/bar"},
+		{"copylocks", "foo"},
+		{"printf", "foo/bar"},
+		{"shadow", "foo"},
+		{"copylocks", "foo/bar"},
+	}
+	for i, tc := range []struct {
+		name        string
+		limiter     *limit.Limiter
+		wantAllowed []bool
+		wantSummary string
+	}{
+		{
+			name:        "unlimited",
+			limiter:     &limit.Limiter{},
+			wantAllowed: []bool{true, true, true, true, true, true, true},
+		},
+		{
+			name:        "max",
+			limiter:     &limit.Limiter{Max: 3},
+			wantAllowed: []bool{true, true, true, false, false, false, false},
+			wantSummary: `govet: omitted 4 of 7 findings because the limit of 3 findings was reached:
+  ANALYZER   PACKAGE  OMITTED
+  copylocks  foo      1
+  copylocks  foo/bar  1
+  printf     foo/bar  1
+  shadow     foo      1`,
+		},
+		{
+			name:        "max per analyzer",
+			limiter:     &limit.Limiter{MaxPerAnalyzer: 1},
+			wantAllowed: []bool{true, false, false, true, false, true, false},
+			wantSummary: `govet: omitted 4 of 7 findings because the limit of 1 finding per analyzer was reached:
+  ANALYZER   PACKAGE  OMITTED
+  printf     foo/bar  2
+  copylocks  foo/bar  1
+  printf     foo      1`,
+		},
+		{
+			name:        "max and max per analyzer",
+			limiter:     &limit.Limiter{Max: 2, MaxPerAnalyzer: 1},
+			wantAllowed: []bool{true, false, false, true, false, false, false},
+			wantSummary: `govet: omitted 5 of 7 findings because the limits of 2 findings and 1 finding per analyzer were reached:
+  ANALYZER   PACKAGE  OMITTED
+  printf     foo/bar  2
+  copylocks  foo/bar  1
+  printf     foo      1
+  shadow     foo      1`,
+		},
+	} {
+		var allowed []bool
+		for _, f := range findings {
+			allowed = append(allowed, tc.limiter.Allow(f.analyzer, f.pkg))
+		}
+		assert.Equal(t, tc.wantAllowed, allowed, "Case %d: %s", i, tc.name)
+		assert.Equal(t, tc.wantSummary, tc.limiter.Summary(), "Case %d: %s", i, tc.name)
+	}
+}
@@ -0,0 +1,162 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package moderr recognizes the module and vendoring errors that the go command writes to standard error. The go
+// command writes such errors over multiple lines: a line that describes the error followed by indented lines with
+// details and instructions, which are grouped into a single Error with a remediation.
+package moderr
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Kind is the kind of a module or vendoring error.
+type Kind string
+
+const (
+	// InconsistentVendoring is the kind of errors for vendor/modules.txt files that are not consistent with go.mod.
+	InconsistentVendoring Kind = "inconsistent-vendoring"
+	// MissingVendor is the kind of errors for packages that are not in the vendor directory.
+	MissingVendor Kind = "missing-vendor"
+	// MissingGoSum is the kind of errors for go.sum files that lack the checksums of required modules.
+	MissingGoSum Kind = "missing-go-sum"
+	// GoModUpdate is the kind of errors for go.mod files that must be updated.
+	GoModUpdate Kind = "go-mod-update"
+	// MissingModule is the kind of errors for imported packages that are not provided by any required module.
+	MissingModule Kind = "missing-module"
+	// Download is the kind of errors for modules that could not be downloaded or verified.
+	Download Kind = "download"
+)
+
+// Error is a module or vendoring error reported by the go command.
+type Error struct {
+	Kind Kind
+	// Message is the line that describes the error without the "go: " prefix.
+	Message string
+	// Details are the indented lines that describe the error, not including the instructions of the go command.
+	Details []string
+	// Fix describes how to fix the error.
+	Fix string
+}
+
+// Error returns the message, details and fix of the error. The details and the fix are on separate lines that are
+// indented by a tab.
+func (e *Error) Error() string {
+	lines := []string{e.Message}
+	for _, detail := range e.Details {
+		lines = append(lines, "\t"+detail)
+	}
+	if e.Fix != "" {
+		lines = append(lines, "\tto fix: "+e.Fix)
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Vendor returns true if the error is caused by a vendor directory that is not consistent with go.mod, which is the
+// case for errors that ignoring the vendor directory may work around.
+func (e *Error) Vendor() bool {
+	return e.Kind == InconsistentVendoring || e.Kind == MissingVendor
+}
+
+var (
+	// moduleVersionRegexp matches messages that start with a module path and version, which the go command uses for
+	// errors that occur while downloading or verifying the module.
+	moduleVersionRegexp = regexp.MustCompile(`^\S+@v\S+: `)
+	// progressRegexp matches the lines that the go command writes to report progress.
+	progressRegexp = regexp.MustCompile(`^go: (downloading|extracting|finding) \S+ \S+$`)
+	// instructionRegexp matches the indented lines that the go command writes to describe how an error can be fixed.
+	instructionRegexp = regexp.MustCompile(`^(To ignore the vendor directory|To sync the vendor directory|go (get|mod|work) )`)
+	// toFixSuffixRegexp matches the suffix that introduces the command that the go command suggests to fix an error.
+	toFixSuffixRegexp = regexp.MustCompile(`; to (add|add it|update it):$`)
+)
+
+// IsProgress returns true if the provided line is written by the go command to report progress, such as the
+// download of a module, rather than to report an error.
+func IsProgress(line string) bool {
+	return progressRegexp.MatchString(line)
+}
+
+// IsContinuation returns true if the provided line continues the error on the preceding line. The go command indents
+// the lines that continue an error and separates paragraphs within an error with empty lines.
+func IsContinuation(line string) bool {
+	return line == "" || strings.HasPrefix(line, "\t") || strings.HasPrefix(line, " ")
+}
+
+// Parse returns the module or vendoring error that is described by the provided lines, which consist of a line that
+// describes the error and the lines that continue it. If the error has a position, the first line must not include
+// the position. Returns nil if the lines do not describe a module or vendoring error.
+func Parse(lines []string) *Error {
+	if len(lines) == 0 {
+		return nil
+	}
+	message := strings.TrimPrefix(lines[0], "go: ")
+	var details, suggested []string
+	for _, line := range lines[1:] {
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		if instructionRegexp.MatchString(line) {
+			if strings.HasPrefix(line, "go ") {
+				suggested = append(suggested, line)
+			}
+			continue
+		}
+		details = append(details, line)
+	}
+	// the command that the go command suggests is more specific than the generic fix for the kind of error
+	suggestedFix := func(fallback string) string {
+		if len(suggested) == 0 {
+			return fallback
+		}
+		return fmt.Sprintf("run %q", strings.Join(suggested, "; "))
+	}
+	if toFixSuffixRegexp.MatchString(message) {
+		message = strings.TrimSuffix(toFixSuffixRegexp.ReplaceAllString(message, ""), ";")
+	}
+
+	err := &Error{
+		Message: message,
+		Details: details,
+	}
+	switch {
+	case strings.Contains(message, "inconsistent vendoring"):
+		err.Kind = InconsistentVendoring
+		vendorCmd := "go mod vendor"
+		if len(suggested) > 0 {
+			vendorCmd = suggested[len(suggested)-1]
+		}
+		err.Fix = fmt.Sprintf("run %q to sync the vendor directory with go.mod, or set -mod=mod or -mod=readonly to ignore the vendor directory", vendorCmd)
+	case strings.Contains(message, "import lookup disabled by -mod=vendor") || strings.Contains(message, "is not in vendor/modules.txt"):
+		err.Kind = MissingVendor
+		err.Fix = `run "go mod vendor" to add the package to the vendor directory`
+	case strings.Contains(message, "missing go.sum entry"):
+		err.Kind = MissingGoSum
+		err.Fix = suggestedFix(`run "go mod tidy" to add the missing entries to go.sum`)
+	case strings.Contains(message, "updates to go.mod needed"):
+		err.Kind = GoModUpdate
+		err.Fix = suggestedFix(`run "go mod tidy" to update go.mod`)
+	case strings.Contains(message, "cannot find module providing package") || strings.Contains(message, "no required module provides package"):
+		err.Kind = MissingModule
+		err.Fix = suggestedFix(`run "go get" for the module that provides the package or "go mod tidy" to add it to go.mod`)
+	case moduleVersionRegexp.MatchString(message) || strings.Contains(message, "module lookup disabled") || strings.Contains(message, "verifying module"):
+		err.Kind = Download
+		err.Fix = "make sure that the module proxy is reachable and that GOPROXY, GOPRIVATE, GONOSUMDB and GOFLAGS are set correctly, or vendor the dependencies using \"go mod vendor\""
+	default:
+		return nil
+	}
+	return err
+}
@@ -0,0 +1,165 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package moderr_test
+
+import (
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/moderr"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParse(t *testing.T) {
+	for i, tc := range []struct {
+		name  string
+		lines []string
+		want  *moderr.Error
+	}{
+		{
+			name: "inconsistent vendoring",
+			lines: []string{
+				"go: inconsistent vendoring in /tmp/foo:",
+				"\tgithub.com/pkg/errors@v0.9.1: is explicitly required in go.mod, but not marked as explicit in vendor/modules.txt",
+				"\tgithub.com/pkg/errors@v0.8.0: is marked as explicit in vendor/modules.txt, but not explicitly required in go.mod",
+				"",
+				"\tTo ignore the vendor directory, use -mod=readonly or -mod=mod.",
+				"\tTo sync the vendor directory, run:",
+				"\t\tgo mod vendor",
+			},
+			want: &moderr.Error{
+				Kind:    moderr.InconsistentVendoring,
+				Message: "inconsistent vendoring in /tmp/foo:",
+				Details: []string{
+					"github.com/pkg/errors@v0.9.1: is explicitly required in go.mod, but not marked as explicit in vendor/modules.txt",
+					"github.com/pkg/errors@v0.8.0: is marked as explicit in vendor/modules.txt, but not explicitly required in go.mod",
+				},
+				Fix: `run "go mod vendor" to sync the vendor directory with go.mod, or set -mod=mod or -mod=readonly to ignore the vendor directory`,
+			},
+		},
+		{
+			name: "inconsistent vendoring in workspace",
+			lines: []string{
+				"go: inconsistent vendoring in /tmp/foo:",
+				"\tfoo/a: is marked as a workspace module in vendor/modules.txt, but not listed in go.work",
+				"",
+				"\tTo ignore the vendor directory, use -mod=readonly or -mod=mod.",
+				"\tTo sync the vendor directory, run:",
+				"\t\tgo work vendor",
+			},
+			want: &moderr.Error{
+				Kind:    moderr.InconsistentVendoring,
+				Message: "inconsistent vendoring in /tmp/foo:",
+				Details: []string{
+					"foo/a: is marked as a workspace module in vendor/modules.txt, but not listed in go.work",
+				},
+				Fix: `run "go work vendor" to sync the vendor directory with go.mod, or set -mod=mod or -mod=readonly to ignore the vendor directory`,
+			},
+		},
+		{
+			name: "package missing from vendor directory",
+			lines: []string{
+				"cannot find module providing package github.com/pkg/errors: import lookup disabled by -mod=vendor",
+			},
+			want: &moderr.Error{
+				Kind:    moderr.MissingVendor,
+				Message: "cannot find module providing package github.com/pkg/errors: import lookup disabled by -mod=vendor",
+				Fix:     `run "go mod vendor" to add the package to the vendor directory`,
+			},
+		},
+		{
+			name: "missing go.sum entry with suggested command",
+			lines: []string{
+				"missing go.sum entry for module providing package github.com/pkg/errors (imported by foo); to add:",
+				"\tgo get foo",
+			},
+			want: &moderr.Error{
+				Kind:    moderr.MissingGoSum,
+				Message: "missing go.sum entry for module providing package github.com/pkg/errors (imported by foo)",
+				Fix:     `run "go get foo"`,
+			},
+		},
+		{
+			name: "go.mod update needed",
+			lines: []string{
+				"go: updates to go.mod needed; to update it:",
+				"\tgo mod tidy",
+			},
+			want: &moderr.Error{
+				Kind:    moderr.GoModUpdate,
+				Message: "updates to go.mod needed",
+				Fix:     `run "go mod tidy"`,
+			},
+		},
+		{
+			name: "missing module",
+			lines: []string{
+				"no required module provides package github.com/pkg/errors; to add it:",
+				"\tgo get github.com/pkg/errors",
+			},
+			want: &moderr.Error{
+				Kind:    moderr.MissingModule,
+				Message: "no required module provides package github.com/pkg/errors",
+				Fix:     `run "go get github.com/pkg/errors"`,
+			},
+		},
+		{
+			name: "download failure",
+			lines: []string{
+				"github.com/pkg/errors@v0.9.1: module lookup disabled by GOPROXY=off",
+			},
+			want: &moderr.Error{
+				Kind:    moderr.Download,
+				Message: "github.com/pkg/errors@v0.9.1: module lookup disabled by GOPROXY=off",
+				Fix:     `make sure that the module proxy is reachable and that GOPROXY, GOPRIVATE, GONOSUMDB and GOFLAGS are set correctly, or vendor the dependencies using "go mod vendor"`,
+			},
+		},
+		{
+			name: "type error is not a module error",
+			lines: []string{
+				"not enough arguments in call to f",
+				"\thave ()",
+				"\twant (int)",
+			},
+		},
+	} {
+		assert.Equal(t, tc.want, moderr.Parse(tc.lines), "Case %d: %s", i, tc.name)
+	}
+}
+
+func TestErrorString(t *testing.T) {
+	err := &moderr.Error{
+		Kind:    moderr.InconsistentVendoring,
+		Message: "inconsistent vendoring in /tmp/foo:",
+		Details: []string{"github.com/pkg/errors@v0.9.1: is explicitly required in go.mod, but not marked as explicit in vendor/modules.txt"},
+		Fix:     `run "go mod vendor"`,
+	}
+	assert.Equal(t, `inconsistent vendoring in /tmp/foo:
+	github.com/pkg/errors@v0.9.1: is explicitly required in go.mod, but not marked as explicit in vendor/modules.txt
+	to fix: run "go mod vendor"`, err.Error())
+}
+
+func TestIsProgress(t *testing.T) {
+	for i, tc := range []struct {
+		line string
+		want bool
+	}{
+		{"go: downloading github.com/pkg/errors v0.9.1", true},
+		{"go: extracting github.com/pkg/errors v0.9.1", true},
+		{"go: inconsistent vendoring in /tmp/foo:", false},
+		{"foo.go:1:1: downloading", false},
+	} {
+		assert.Equal(t, tc.want, moderr.IsProgress(tc.line), "Case %d: %s", i, tc.line)
+	}
+}
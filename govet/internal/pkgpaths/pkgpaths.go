@@ -0,0 +1,297 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package pkgpaths resolves the package arguments that okgo provides to a checker into canonical import paths or
+// import path patterns that can be provided to "go vet" regardless of how the input paths were spelled.
+package pkgpaths
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"github.com/pkg/errors"
+	"golang.org/x/mod/modfile"
+)
+
+// Resolve converts the provided package arguments into import paths or import path patterns. Relative inputs are
+// interpreted relative to wd. Inputs that are already import paths (that is, inputs that are neither relative nor
+// absolute file paths) are returned unmodified. The returned paths are in the same order as the inputs from which
+// they were derived. An error is returned for every input that cannot be resolved: such inputs do not contribute to
+// the returned paths. getenv returns the values of the environment variables of the go command to which the paths are
+// provided, which determine whether modules and workspaces are in effect.
+func Resolve(inputs []string, wd string, getenv func(key string) string) ([]string, []error) {
+	r, err := newResolver(wd, getenv)
+	if err != nil {
+		errs := make([]error, len(inputs))
+		for i := range inputs {
+			errs[i] = errors.Wrapf(err, "failed to resolve package %q", inputs[i])
+		}
+		return nil, errs
+	}
+
+	var (
+		resolved []string
+		errs     []error
+	)
+	for _, input := range inputs {
+		pkgPath, err := r.resolve(input)
+		if err != nil {
+			errs = append(errs, errors.Wrapf(err, "failed to resolve package %q", input))
+			continue
+		}
+		resolved = append(resolved, pkgPath)
+	}
+	return resolved, errs
+}
+
+type module struct {
+	dir  string
+	path string
+}
+
+type resolver struct {
+	// wd is the working directory with all symlinks evaluated.
+	wd string
+	// gopathMode is true if modules are disabled, in which case relative paths are passed through to "go vet".
+	gopathMode bool
+	// mainModules contains the modules whose packages may be provided to "go vet", keyed by their directory. This is
+	// the module that contains the working directory, or all the modules in the workspace if a go.work file is in
+	// effect.
+	mainModules map[string]module
+}
+
+func newResolver(wd string, getenv func(key string) string) (*resolver, error) {
+	realWd, err := filepath.EvalSymlinks(wd)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to evaluate symlinks in working directory %s", wd)
+	}
+	r := &resolver{
+		wd:          realWd,
+		gopathMode:  getenv("GO111MODULE") == "off",
+		mainModules: make(map[string]module),
+	}
+	if r.gopathMode {
+		return r, nil
+	}
+
+	workFile, err := findWorkFile(realWd, getenv("GOWORK"))
+	if err != nil {
+		return nil, err
+	}
+	if workFile != "" {
+		if err := r.addWorkspaceModules(workFile); err != nil {
+			return nil, err
+		}
+		return r, nil
+	}
+
+	mod, ok, err := findModule(realWd)
+	if err != nil {
+		return nil, err
+	}
+	if !ok {
+		return nil, errors.Errorf("no go.mod file found in %s or any of its parent directories", wd)
+	}
+	r.mainModules[mod.dir] = mod
+	return r, nil
+}
+
+func (r *resolver) addWorkspaceModules(workFile string) error {
+	workBytes, err := os.ReadFile(workFile)
+	if err != nil {
+		return errors.Wrapf(err, "failed to read %s", workFile)
+	}
+	work, err := modfile.ParseWork(workFile, workBytes, nil)
+	if err != nil {
+		return errors.Wrapf(err, "failed to parse %s", workFile)
+	}
+	for _, use := range work.Use {
+		modDir := use.Path
+		if !filepath.IsAbs(modDir) {
+			modDir = filepath.Join(filepath.Dir(workFile), modDir)
+		}
+		realModDir, err := filepath.EvalSymlinks(modDir)
+		if err != nil {
+			return errors.Wrapf(err, "failed to evaluate symlinks in workspace module directory %s", modDir)
+		}
+		mod, ok, err := readModule(realModDir)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			return errors.Errorf("workspace module directory %s does not contain a go.mod file", modDir)
+		}
+		r.mainModules[mod.dir] = mod
+	}
+	return nil
+}
+
+func (r *resolver) resolve(input string) (string, error) {
+	if !isFilePath(input) {
+		// input is already an import path or pattern
+		return input, nil
+	}
+
+	dirPart, wildcardPart := splitWildcard(input)
+	absDir := filepath.FromSlash(dirPart)
+	if !filepath.IsAbs(absDir) {
+		absDir = filepath.Join(r.wd, absDir)
+	}
+	realDir, err := filepath.EvalSymlinks(absDir)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return "", errors.Errorf("directory %s does not exist", absDir)
+		}
+		return "", errors.Wrapf(err, "failed to evaluate symlinks in %s", absDir)
+	}
+	fi, err := os.Stat(realDir)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to stat %s", realDir)
+	}
+	if !fi.IsDir() {
+		return "", errors.Errorf("%s is a file rather than a package directory", absDir)
+	}
+
+	if r.gopathMode {
+		return relativePattern(r.wd, realDir, wildcardPart)
+	}
+
+	mod, ok, err := findModule(realDir)
+	if err != nil {
+		return "", err
+	}
+	if !ok {
+		return "", errors.Errorf("directory %s is not in a module", realDir)
+	}
+	if _, ok := r.mainModules[mod.dir]; !ok {
+		return "", errors.Errorf("directory %s is in module %s (%s), which is not a main module", realDir, mod.path, mod.dir)
+	}
+
+	relDir, err := filepath.Rel(mod.dir, realDir)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to determine path of %s relative to module directory %s", realDir, mod.dir)
+	}
+	relDir = filepath.ToSlash(relDir)
+	if relDir == "vendor" || strings.HasPrefix(relDir, "vendor/") {
+		return "", errors.Errorf("directory %s is in the vendor directory of module %s", realDir, mod.path)
+	}
+	return path.Join(mod.path, relDir, wildcardPart), nil
+}
+
+// isFilePath returns true if the provided input should be interpreted as a file path rather than as an import path.
+// This matches the rules used by the go command: an argument is a file path if it is absolute or if it is ".", ".."
+// or starts with "./" or "../".
+func isFilePath(input string) bool {
+	if filepath.IsAbs(input) {
+		return true
+	}
+	input = filepath.ToSlash(input)
+	return input == "." || input == ".." || strings.HasPrefix(input, "./") || strings.HasPrefix(input, "../")
+}
+
+// splitWildcard splits the provided path into the longest prefix of path elements that do not contain the "..."
+// wildcard and the remainder, which starts with the first element that contains a wildcard. The remainder is empty if
+// the path does not contain a wildcard.
+func splitWildcard(input string) (string, string) {
+	elems := strings.Split(filepath.ToSlash(input), "/")
+	for i, elem := range elems {
+		if strings.Contains(elem, "...") {
+			dir := strings.Join(elems[:i], "/")
+			if dir == "" {
+				// wildcard in first element of relative path or in root element of absolute path
+				dir = "."
+				if strings.HasPrefix(input, "/") {
+					dir = "/"
+				}
+			}
+			return dir, strings.Join(elems[i:], "/")
+		}
+	}
+	return input, ""
+}
+
+// relativePattern returns a pattern for dir with the provided wildcard suffix that is relative to wd. The returned
+// pattern always starts with "./" or "../" so that it is not interpreted as an import path.
+func relativePattern(wd, dir, wildcardPart string) (string, error) {
+	rel, err := filepath.Rel(wd, dir)
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to determine path of %s relative to %s", dir, wd)
+	}
+	rel = path.Join(filepath.ToSlash(rel), wildcardPart)
+	if rel != "." && rel != ".." && !strings.HasPrefix(rel, "../") {
+		rel = "./" + rel
+	}
+	return rel, nil
+}
+
+// findWorkFile returns the path to the go.work file that is in effect for the provided directory given the value of the
+// GOWORK environment variable, or an empty string if workspace mode is not in effect. The logic mirrors that of the go
+// command: the GOWORK environment variable takes precedence, and otherwise the directory and its parents are searched
+// for a go.work file.
+func findWorkFile(dir, gowork string) (string, error) {
+	switch gowork {
+	case "off":
+		return "", nil
+	case "", "auto":
+	default:
+		return gowork, nil
+	}
+	for currDir := dir; ; currDir = filepath.Dir(currDir) {
+		workFile := filepath.Join(currDir, "go.work")
+		if fi, err := os.Stat(workFile); err == nil && !fi.IsDir() {
+			return workFile, nil
+		} else if err != nil && !os.IsNotExist(err) {
+			return "", errors.Wrapf(err, "failed to stat %s", workFile)
+		}
+		if parent := filepath.Dir(currDir); parent == currDir {
+			return "", nil
+		}
+	}
+}
+
+// findModule returns the module that contains the provided directory. Returns false if the directory is not in a
+// module.
+func findModule(dir string) (module, bool, error) {
+	for currDir := dir; ; currDir = filepath.Dir(currDir) {
+		mod, ok, err := readModule(currDir)
+		if err != nil || ok {
+			return mod, ok, err
+		}
+		if parent := filepath.Dir(currDir); parent == currDir {
+			return module{}, false, nil
+		}
+	}
+}
+
+// readModule returns the module defined by the go.mod file in the provided directory. Returns false if the directory
+// does not contain a go.mod file.
+func readModule(dir string) (module, bool, error) {
+	goModFile := filepath.Join(dir, "go.mod")
+	goModBytes, err := os.ReadFile(goModFile)
+	if os.IsNotExist(err) {
+		return module{}, false, nil
+	} else if err != nil {
+		return module{}, false, errors.Wrapf(err, "failed to read %s", goModFile)
+	}
+	modPath := modfile.ModulePath(goModBytes)
+	if modPath == "" {
+		return module{}, false, errors.Errorf("%s does not declare a module path", goModFile)
+	}
+	return module{
+		dir:  dir,
+		path: modPath,
+	}, true, nil
+}
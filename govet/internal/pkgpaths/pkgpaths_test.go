@@ -0,0 +1,197 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package pkgpaths_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/pkgpaths"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	getenv := envFunc(map[string]string{"GOWORK": "off", "GO111MODULE": "on"})
+
+	rootDir := evalSymlinks(t, t.TempDir())
+	projectDir := filepath.Join(rootDir, "project")
+	writeFiles(t, projectDir, map[string]string{
+		"go.mod":               "module github.com/org/project\n",
+		"foo.go":               "package foo\n",
+		"bar/bar.go":           "package bar\n",
+		"bar/baz/baz.go":       "package baz\n",
+		"inner/.keep":          "",
+		"nested/go.mod":        "module github.com/org/nested\n",
+		"nested/nested.go":     "package nested\n",
+		"vendor/dep/dep.go":    "package dep\n",
+		"vendor/modules.txt":   "",
+		"../outside/other.go":  "package other\n",
+		"../outside/other2.go": "package other\n",
+	})
+	require.NoError(t, os.Symlink(projectDir, filepath.Join(rootDir, "link")))
+
+	for i, tc := range []struct {
+		name       string
+		wd         string
+		inputs     []string
+		wantPaths  []string
+		wantErrors []string
+	}{
+		{
+			name:      "relative paths from module root",
+			wd:        projectDir,
+			inputs:    []string{".", "./bar", "./bar/baz"},
+			wantPaths: []string{"github.com/org/project", "github.com/org/project/bar", "github.com/org/project/bar/baz"},
+		},
+		{
+			name:      "relative paths that start with ./.. from inner directory",
+			wd:        filepath.Join(projectDir, "inner"),
+			inputs:    []string{"./..", "./../bar", "../bar/baz"},
+			wantPaths: []string{"github.com/org/project", "github.com/org/project/bar", "github.com/org/project/bar/baz"},
+		},
+		{
+			name:      "absolute paths",
+			wd:        filepath.Join(projectDir, "inner"),
+			inputs:    []string{projectDir, filepath.Join(projectDir, "bar")},
+			wantPaths: []string{"github.com/org/project", "github.com/org/project/bar"},
+		},
+		{
+			name:      "symlinked working directory and paths",
+			wd:        filepath.Join(rootDir, "link", "inner"),
+			inputs:    []string{"../bar", filepath.Join(rootDir, "link", "bar", "baz")},
+			wantPaths: []string{"github.com/org/project/bar", "github.com/org/project/bar/baz"},
+		},
+		{
+			name:      "wildcards",
+			wd:        projectDir,
+			inputs:    []string{"./...", "./bar/...", "./b...", filepath.Join(projectDir, "bar", "...")},
+			wantPaths: []string{"github.com/org/project/...", "github.com/org/project/bar/...", "github.com/org/project/b...", "github.com/org/project/bar/..."},
+		},
+		{
+			name:      "import paths are not modified",
+			wd:        projectDir,
+			inputs:    []string{"github.com/org/project/bar", "github.com/org/project/...", "std"},
+			wantPaths: []string{"github.com/org/project/bar", "github.com/org/project/...", "std"},
+		},
+		{
+			name:      "unresolvable paths are reported",
+			wd:        projectDir,
+			inputs:    []string{"./missing", "./foo.go", "../outside", "./nested", "./vendor/dep", "./bar"},
+			wantPaths: []string{"github.com/org/project/bar"},
+			wantErrors: []string{
+				`failed to resolve package "./missing": directory ` + filepath.Join(projectDir, "missing") + ` does not exist`,
+				`failed to resolve package "./foo.go": ` + filepath.Join(projectDir, "foo.go") + ` is a file rather than a package directory`,
+				`failed to resolve package "../outside": directory ` + filepath.Join(rootDir, "outside") + ` is not in a module`,
+				`failed to resolve package "./nested": directory ` + filepath.Join(projectDir, "nested") + ` is in module github.com/org/nested (` + filepath.Join(projectDir, "nested") + `), which is not a main module`,
+				`failed to resolve package "./vendor/dep": directory ` + filepath.Join(projectDir, "vendor", "dep") + ` is in the vendor directory of module github.com/org/project`,
+			},
+		},
+	} {
+		gotPaths, gotErrs := pkgpaths.Resolve(tc.inputs, tc.wd, getenv)
+		assert.Equal(t, tc.wantPaths, gotPaths, "Case %d: %s", i, tc.name)
+		var gotErrStrings []string
+		for _, err := range gotErrs {
+			gotErrStrings = append(gotErrStrings, err.Error())
+		}
+		assert.Equal(t, tc.wantErrors, gotErrStrings, "Case %d: %s", i, tc.name)
+	}
+}
+
+func TestResolveWorkspace(t *testing.T) {
+	getenv := envFunc(map[string]string{"GO111MODULE": "on"})
+
+	rootDir := evalSymlinks(t, t.TempDir())
+	writeFiles(t, rootDir, map[string]string{
+		"go.work":      "go 1.21\n\nuse (\n\t./a\n\t./b\n)\n",
+		"a/go.mod":     "module example.com/a\n",
+		"a/a.go":       "package a\n",
+		"b/go.mod":     "module example.com/b\n",
+		"b/sub/sub.go": "package sub\n",
+		"c/go.mod":     "module example.com/c\n",
+		"c/c.go":       "package c\n",
+	})
+
+	gotPaths, gotErrs := pkgpaths.Resolve([]string{".", "../b/sub", "../b/...", "../c"}, filepath.Join(rootDir, "a"), getenv)
+	assert.Equal(t, []string{"example.com/a", "example.com/b/sub", "example.com/b/..."}, gotPaths)
+	require.Len(t, gotErrs, 1)
+	assert.EqualError(t, gotErrs[0], `failed to resolve package "../c": directory `+filepath.Join(rootDir, "c")+` is in module example.com/c (`+filepath.Join(rootDir, "c")+`), which is not a main module`)
+}
+
+func TestResolveWorkspaceOff(t *testing.T) {
+	getenv := envFunc(map[string]string{"GOWORK": "off", "GO111MODULE": "on"})
+
+	rootDir := evalSymlinks(t, t.TempDir())
+	writeFiles(t, rootDir, map[string]string{
+		"go.work":      "go 1.21\n\nuse (\n\t./a\n\t./b\n)\n",
+		"a/go.mod":     "module example.com/a\n",
+		"a/a.go":       "package a\n",
+		"b/go.mod":     "module example.com/b\n",
+		"b/sub/sub.go": "package sub\n",
+	})
+
+	// the go.work file is ignored, so only the module that contains the working directory is a main module
+	gotPaths, gotErrs := pkgpaths.Resolve([]string{".", "../b/sub"}, filepath.Join(rootDir, "a"), getenv)
+	assert.Equal(t, []string{"example.com/a"}, gotPaths)
+	require.Len(t, gotErrs, 1)
+	assert.EqualError(t, gotErrs[0], `failed to resolve package "../b/sub": directory `+filepath.Join(rootDir, "b", "sub")+` is in module example.com/b (`+filepath.Join(rootDir, "b")+`), which is not a main module`)
+}
+
+func TestResolveNoModule(t *testing.T) {
+	getenv := envFunc(map[string]string{"GOWORK": "off", "GO111MODULE": "on"})
+
+	wd := evalSymlinks(t, t.TempDir())
+	gotPaths, gotErrs := pkgpaths.Resolve([]string{"."}, wd, getenv)
+	assert.Empty(t, gotPaths)
+	require.Len(t, gotErrs, 1)
+	assert.EqualError(t, gotErrs[0], `failed to resolve package ".": no go.mod file found in `+wd+` or any of its parent directories`)
+}
+
+func TestResolveGOPATHMode(t *testing.T) {
+	getenv := envFunc(map[string]string{"GO111MODULE": "off"})
+
+	wd := evalSymlinks(t, t.TempDir())
+	writeFiles(t, wd, map[string]string{
+		"foo/foo.go":   "package foo\n",
+		"inner/bar.go": "package bar\n",
+	})
+
+	gotPaths, gotErrs := pkgpaths.Resolve([]string{"./..", "./../foo/...", "."}, filepath.Join(wd, "inner"), getenv)
+	assert.Equal(t, []string{"..", "../foo/...", "."}, gotPaths)
+	assert.Empty(t, gotErrs)
+}
+
+// envFunc returns a function that returns the values of the provided environment variables, and an empty string for
+// all other variables.
+func envFunc(env map[string]string) func(key string) string {
+	return func(key string) string {
+		return env[key]
+	}
+}
+
+func evalSymlinks(t *testing.T, path string) string {
+	realPath, err := filepath.EvalSymlinks(path)
+	require.NoError(t, err)
+	return realPath
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for relPath, content := range files {
+		filePath := filepath.Join(dir, relPath)
+		require.NoError(t, os.MkdirAll(filepath.Dir(filePath), 0755))
+		require.NoError(t, os.WriteFile(filePath, []byte(content), 0644))
+	}
+}
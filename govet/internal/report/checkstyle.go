@@ -0,0 +1,143 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"encoding/xml"
+	"io"
+	"path/filepath"
+	"sort"
+
+	"github.com/palantir/okgo/okgo"
+	"github.com/pkg/errors"
+)
+
+type checkstyleLog struct {
+	XMLName xml.Name         `xml:"checkstyle"`
+	Version string           `xml:"version,attr"`
+	Files   []checkstyleFile `xml:"file"`
+}
+
+type checkstyleFile struct {
+	Name   string            `xml:"name,attr"`
+	Errors []checkstyleError `xml:"error"`
+}
+
+type checkstyleError struct {
+	Line     int    `xml:"line,attr"`
+	Column   int    `xml:"column,attr,omitempty"`
+	Severity string `xml:"severity,attr"`
+	Message  string `xml:"message,attr"`
+	Source   string `xml:"source,attr"`
+}
+
+// WriteCheckstyle writes the provided results to w in the Checkstyle XML format. Every diagnostic is reported with its
+// severity and the source "govet.<analyzer>", and its related information is reported as "info" entries at the related
+// positions. Errors that prevented packages from being vetted are reported with the
+// source "govet" if they refer to a file. File names in projectDir are written relative to projectDir.
+func WriteCheckstyle(w io.Writer, results Results, projectDir string) error {
+	fileErrors := make(map[string][]checkstyleError)
+	for _, diag := range results.Diagnostics {
+		if !diag.Pos.IsValid() {
+			continue
+		}
+		name := reportPath(diag.Pos.Filename, projectDir)
+		fileErrors[name] = append(fileErrors[name], checkstyleError{
+			Line:     diag.Pos.Line,
+			Column:   diag.Pos.Column,
+			Severity: Severity(diag),
+			Message:  diag.Message,
+			Source:   "govet." + diag.Analyzer,
+		})
+		// related information is reported as informational entries at the related locations
+		for _, related := range diag.Related {
+			if !related.Pos.IsValid() {
+				continue
+			}
+			relatedName := reportPath(related.Pos.Filename, projectDir)
+			fileErrors[relatedName] = append(fileErrors[relatedName], checkstyleError{
+				Line:     related.Pos.Line,
+				Column:   related.Pos.Column,
+				Severity: "info",
+				Message:  related.Message + " (related to: " + diag.Message + ")",
+				Source:   "govet." + diag.Analyzer,
+			})
+		}
+	}
+	for _, pkg := range sortedKeys(results.Errors) {
+		for _, issue := range results.Errors[pkg] {
+			if issue.Path == "" {
+				continue
+			}
+			name := reportPath(issue.Path, projectDir)
+			fileErrors[name] = append(fileErrors[name], checkstyleErrorFromIssue(issue))
+		}
+	}
+
+	log := checkstyleLog{
+		Version: "8.0",
+	}
+	for _, name := range sortedKeys(fileErrors) {
+		log.Files = append(log.Files, checkstyleFile{
+			Name:   name,
+			Errors: fileErrors[name],
+		})
+	}
+	if err := writeXML(w, log); err != nil {
+		return errors.Wrapf(err, "failed to write Checkstyle report")
+	}
+	return nil
+}
+
+func checkstyleErrorFromIssue(issue okgo.Issue) checkstyleError {
+	return checkstyleError{
+		Line:     issue.Line,
+		Column:   issue.Col,
+		Severity: "error",
+		Message:  issue.Content,
+		Source:   "govet",
+	}
+}
+
+// reportPath returns the path of filename relative to projectDir if it is in projectDir and the cleaned path
+// otherwise. The returned path always uses forward slashes.
+func reportPath(filename, projectDir string) string {
+	if rel, ok := relPath(filename, projectDir); ok {
+		return filepath.ToSlash(rel)
+	}
+	return filepath.ToSlash(filepath.Clean(filename))
+}
+
+func writeXML(w io.Writer, v any) error {
+	if _, err := io.WriteString(w, xml.Header); err != nil {
+		return err
+	}
+	encoder := xml.NewEncoder(w)
+	encoder.Indent("", "  ")
+	if err := encoder.Encode(v); err != nil {
+		return err
+	}
+	_, err := io.WriteString(w, "\n")
+	return err
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
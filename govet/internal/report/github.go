@@ -0,0 +1,127 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"fmt"
+	"path/filepath"
+	"strings"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/severity"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/vetjson"
+	"github.com/palantir/okgo/okgo"
+)
+
+// Annotation is an annotation of a source location in GitHub Actions.
+type Annotation struct {
+	// Level is the annotation level: one of "error", "warning" or "notice".
+	Level     string
+	Title     string
+	File      string
+	Line      int
+	Col       int
+	EndLine   int
+	EndColumn int
+	Message   string
+}
+
+// AnnotationFromDiagnostic returns an annotation for the provided diagnostic whose title is the analyzer that reported
+// it. The level of the annotation is derived from the severity of the diagnostic. Related information is rendered as
+// indented lines that follow the message. File names in baseDir are made relative to baseDir.
+func AnnotationFromDiagnostic(diag vetjson.Diagnostic, baseDir string) Annotation {
+	annotation := Annotation{
+		Level:   annotationLevel(Severity(diag)),
+		Title:   diag.Analyzer,
+		Message: strings.Join(append([]string{diag.Message}, relatedLines(diag, baseDir)...), "\n"),
+	}
+	if diag.Pos.IsValid() {
+		annotation.File = reportPath(diag.Pos.Filename, baseDir)
+		annotation.Line = diag.Pos.Line
+		annotation.Col = diag.Pos.Column
+		if diag.End.IsValid() && diag.End.Filename == diag.Pos.Filename {
+			annotation.EndLine = diag.End.Line
+			annotation.EndColumn = diag.End.Column
+		}
+	}
+	return annotation
+}
+
+// AnnotationFromIssue returns an error annotation with the title "govet" for the provided issue. The path of the issue
+// is interpreted relative to wd, and file names in baseDir are made relative to baseDir.
+func AnnotationFromIssue(issue okgo.Issue, wd, baseDir string) Annotation {
+	annotation := Annotation{
+		Level:   "error",
+		Title:   "govet",
+		Message: issue.Content,
+	}
+	if issue.Path != "" {
+		path := issue.Path
+		if !filepath.IsAbs(path) {
+			path = filepath.Join(wd, path)
+		}
+		annotation.File = reportPath(path, baseDir)
+		annotation.Line = issue.Line
+		annotation.Col = issue.Col
+	}
+	return annotation
+}
+
+// annotationLevel returns the annotation level for the provided severity.
+func annotationLevel(sev string) string {
+	switch sev {
+	case severity.Warning:
+		return "warning"
+	case severity.Info:
+		return "notice"
+	default:
+		return "error"
+	}
+}
+
+// String returns the annotation as a GitHub Actions workflow command.
+func (a Annotation) String() string {
+	var props []string
+	addProp := func(name, value string) {
+		props = append(props, name+"="+escapeProperty(value))
+	}
+	addIntProp := func(name string, value int) {
+		if value > 0 {
+			addProp(name, fmt.Sprint(value))
+		}
+	}
+	if a.File != "" {
+		addProp("file", a.File)
+	}
+	addIntProp("line", a.Line)
+	addIntProp("col", a.Col)
+	addIntProp("endLine", a.EndLine)
+	addIntProp("endColumn", a.EndColumn)
+	if a.Title != "" {
+		addProp("title", a.Title)
+	}
+	cmd := "::" + a.Level
+	if len(props) > 0 {
+		cmd += " " + strings.Join(props, ",")
+	}
+	return cmd + "::" + escapeData(a.Message)
+}
+
+func escapeData(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A").Replace(s)
+}
+
+func escapeProperty(s string) string {
+	return strings.NewReplacer("%", "%25", "\r", "%0D", "\n", "%0A", ":", "%3A", ",", "%2C").Replace(s)
+}
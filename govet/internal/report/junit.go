@@ -0,0 +1,167 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"encoding/xml"
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/severity"
+	"github.com/pkg/errors"
+)
+
+// junitVetCaseName is the name of the test case used for errors that cannot be attributed to a package.
+const junitVetCaseName = "go vet"
+
+type junitTestSuites struct {
+	XMLName xml.Name         `xml:"testsuites"`
+	Suites  []junitTestSuite `xml:"testsuite"`
+}
+
+type junitTestSuite struct {
+	Name     string          `xml:"name,attr"`
+	Tests    int             `xml:"tests,attr"`
+	Failures int             `xml:"failures,attr"`
+	Errors   int             `xml:"errors,attr"`
+	Cases    []junitTestCase `xml:"testcase"`
+}
+
+type junitTestCase struct {
+	ClassName string        `xml:"classname,attr"`
+	Name      string        `xml:"name,attr"`
+	Failure   *junitProblem `xml:"failure,omitempty"`
+	Error     *junitProblem `xml:"error,omitempty"`
+	SystemOut string        `xml:"system-out,omitempty"`
+}
+
+type junitProblem struct {
+	Message string `xml:"message,attr"`
+	Type    string `xml:"type,attr"`
+	Text    string `xml:",chardata"`
+}
+
+// WriteJUnit writes the provided results to w in the JUnit XML format. The report contains a single test suite named
+// "govet" with one test case per package. A test case fails if analyzers reported diagnostics for its package and
+// errors if its package could not be vetted. Only diagnostics with the "error" severity cause failures, while
+// diagnostics with other severities are listed in the standard output of the test case. The text of failures and errors
+// lists the findings, with file names in projectDir relative to projectDir.
+func WriteJUnit(w io.Writer, results Results, projectDir string) error {
+	pkgDiags := make(map[string][]string)
+	pkgFindings := make(map[string]int)
+	pkgOtherDiags := make(map[string][]string)
+	for _, diag := range results.Diagnostics {
+		pkg := testedPackage(diag.Package, results.Packages)
+		line := fmt.Sprintf("[%s] %s", diag.Analyzer, diag.Message)
+		if diag.Pos.IsValid() {
+			pos := diag.Pos
+			pos.Filename = reportPath(pos.Filename, projectDir)
+			line = pos.String() + ": " + line
+		}
+		lines := append([]string{line}, relatedLines(diag, projectDir)...)
+		if sev := Severity(diag); sev != severity.Error {
+			lines[0] = sev + ": " + lines[0]
+			pkgOtherDiags[pkg] = append(pkgOtherDiags[pkg], lines...)
+			continue
+		}
+		pkgFindings[pkg]++
+		pkgDiags[pkg] = append(pkgDiags[pkg], lines...)
+	}
+	pkgErrors := make(map[string][]string)
+	for pkg, issues := range results.Errors {
+		pkg = testedPackage(pkg, results.Packages)
+		if pkg == "" {
+			pkg = junitVetCaseName
+		}
+		for _, issue := range issues {
+			if issue.Path != "" {
+				issue.Path = reportPath(issue.Path, projectDir)
+			}
+			pkgErrors[pkg] = append(pkgErrors[pkg], issue.String())
+		}
+	}
+
+	pkgs := make(map[string]struct{})
+	for _, pkg := range results.Packages {
+		pkgs[pkg] = struct{}{}
+	}
+	for pkg := range pkgDiags {
+		pkgs[pkg] = struct{}{}
+	}
+	for pkg := range pkgOtherDiags {
+		pkgs[pkg] = struct{}{}
+	}
+	for pkg := range pkgErrors {
+		pkgs[pkg] = struct{}{}
+	}
+
+	suite := junitTestSuite{
+		Name: "govet",
+	}
+	for _, pkg := range sortedKeys(pkgs) {
+		testCase := junitTestCase{
+			ClassName: "govet",
+			Name:      pkg,
+		}
+		if diags := pkgDiags[pkg]; len(diags) > 0 {
+			testCase.Failure = &junitProblem{
+				Message: pluralize(pkgFindings[pkg], "finding"),
+				Type:    "govet",
+				Text:    strings.Join(diags, "\n"),
+			}
+			suite.Failures++
+		}
+		if errs := pkgErrors[pkg]; len(errs) > 0 {
+			testCase.Error = &junitProblem{
+				Message: pluralize(len(errs), "error"),
+				Type:    "govet",
+				Text:    strings.Join(errs, "\n"),
+			}
+			suite.Errors++
+		}
+		if diags := pkgOtherDiags[pkg]; len(diags) > 0 {
+			testCase.SystemOut = strings.Join(diags, "\n")
+		}
+		suite.Cases = append(suite.Cases, testCase)
+	}
+	suite.Tests = len(suite.Cases)
+
+	if err := writeXML(w, junitTestSuites{Suites: []junitTestSuite{suite}}); err != nil {
+		return errors.Wrapf(err, "failed to write JUnit report")
+	}
+	return nil
+}
+
+// testedPackage returns the package that the provided package belongs to. External test packages, whose import
+// paths have a "_test" suffix, belong to the package that they test if that package was vetted.
+func testedPackage(pkg string, vettedPkgs []string) string {
+	testedPkg, ok := strings.CutSuffix(pkg, "_test")
+	if !ok {
+		return pkg
+	}
+	if slices.Contains(vettedPkgs, testedPkg) {
+		return testedPkg
+	}
+	return pkg
+}
+
+func pluralize(n int, noun string) string {
+	if n == 1 {
+		return fmt.Sprintf("%d %s", n, noun)
+	}
+	return fmt.Sprintf("%d %ss", n, noun)
+}
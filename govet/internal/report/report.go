@@ -0,0 +1,101 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package report writes vet diagnostics in structured report formats.
+package report
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/severity"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/vetjson"
+	"github.com/palantir/okgo/okgo"
+	"github.com/pkg/errors"
+)
+
+// Results are the results of vetting a set of packages.
+type Results struct {
+	// Packages are the import paths of the packages that were vetted. May be empty if the packages are not known, in
+	// which case reports only include the packages that produced diagnostics or errors.
+	Packages []string
+	// Diagnostics are the diagnostics reported by analyzers.
+	Diagnostics []vetjson.Diagnostic
+	// Errors are the errors that prevented packages from being vetted, such as type-checking errors, keyed by the
+	// import path of the package. Errors that cannot be attributed to a package are keyed by the empty string.
+	Errors map[string][]okgo.Issue
+}
+
+// Severity returns the severity of the provided diagnostic, which is severity.Error if it was not assigned.
+func Severity(diag vetjson.Diagnostic) string {
+	if diag.Severity == "" {
+		return severity.Error
+	}
+	return diag.Severity
+}
+
+// RelatedLine returns the line used to render related information with the provided position and message in text
+// output.
+func RelatedLine(pos vetjson.Position, message string) string {
+	if !pos.IsValid() {
+		return message
+	}
+	return pos.String() + ": " + message
+}
+
+// relatedLines returns the lines used to render the related information of the provided diagnostic in text output.
+// File names in projectDir are made relative to projectDir. Every line is indented with a tab.
+func relatedLines(diag vetjson.Diagnostic, projectDir string) []string {
+	var lines []string
+	for _, related := range diag.Related {
+		pos := related.Pos
+		if pos.Filename != "" {
+			pos.Filename = reportPath(pos.Filename, projectDir)
+		}
+		lines = append(lines, "\t"+RelatedLine(pos, related.Message))
+	}
+	return lines
+}
+
+// WriteFile creates the file at the provided path, creating its parent directories if necessary, and calls write to
+// write its content.
+func WriteFile(path string, write func(w io.Writer) error) (rErr error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return errors.Wrapf(err, "failed to create directory for report %s", path)
+	}
+	f, err := os.Create(path)
+	if err != nil {
+		return errors.Wrapf(err, "failed to create report %s", path)
+	}
+	defer func() {
+		if err := f.Close(); err != nil && rErr == nil {
+			rErr = errors.Wrapf(err, "failed to close report %s", path)
+		}
+	}()
+	return write(f)
+}
+
+// relPath returns the path of filename relative to dir. Returns false if filename is not in dir.
+func relPath(filename, dir string) (string, bool) {
+	if dir == "" || !filepath.IsAbs(filename) {
+		return filename, !filepath.IsAbs(filename)
+	}
+	rel, err := filepath.Rel(dir, filename)
+	if err != nil || rel == ".." || strings.HasPrefix(rel, ".."+string(filepath.Separator)) {
+		return "", false
+	}
+	return rel, true
+}
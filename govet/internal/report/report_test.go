@@ -0,0 +1,312 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/report"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/vetjson"
+	"github.com/palantir/okgo/okgo"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"golang.org/x/tools/go/analysis"
+)
+
+var (
+	testAnalyzers = []*analysis.Analyzer{
+		{
+			Name: "printf",
+			Doc:  "printf: check consistency of Printf format strings\n\nLonger description.",
+			URL:  "https://pkg.go.dev/golang.org/x/tools/go/analysis/passes/printf",
+		},
+	}
+	testDiagnostics = []vetjson.Diagnostic{
+		{
+			Package:  "example.com/foo",
+			Analyzer: "printf",
+			Pos:      vetjson.Position{Filename: "/project/foo.go", Line: 7, Column: 14},
+			End:      vetjson.Position{Filename: "/project/foo.go", Line: 7, Column: 16},
+			Message:  "fmt.Printf format %s has arg num of wrong type int",
+			SuggestedFixes: []vetjson.SuggestedFix{
+				{
+					Message: "use %d",
+					Edits: []vetjson.TextEdit{
+						{Filename: "/project/foo.go", Start: 60, End: 62, New: "%d"},
+					},
+				},
+			},
+			Related: []vetjson.RelatedInformation{
+				{
+					Pos:     vetjson.Position{Filename: "/project/foo.go", Line: 5, Column: 2},
+					End:     vetjson.Position{Filename: "/project/foo.go", Line: 5, Column: 5},
+					Message: "num declared here",
+				},
+			},
+		},
+		{
+			Package:  "example.com/foo/bar",
+			Analyzer: "custom",
+			Pos:      vetjson.Position{Filename: "/other/bar.go", Line: 3},
+			Message:  "custom finding",
+			Severity: "warning",
+		},
+	}
+)
+
+func TestWriteSARIF(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, report.WriteSARIF(buf, report.Results{Diagnostics: testDiagnostics}, testAnalyzers, "/project"))
+
+	var got map[string]any
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	var want map[string]any
+	require.NoError(t, json.Unmarshal([]byte(`{
+  "version": "2.1.0",
+  "$schema": "https://json.schemastore.org/sarif-2.1.0.json",
+  "runs": [
+    {
+      "tool": {
+        "driver": {
+          "name": "govet",
+          "informationUri": "https://pkg.go.dev/cmd/vet",
+          "rules": [
+            {
+              "id": "printf",
+              "name": "printf",
+              "shortDescription": {"text": "check consistency of Printf format strings"},
+              "fullDescription": {"text": "printf: check consistency of Printf format strings\n\nLonger description."},
+              "help": {"text": "printf: check consistency of Printf format strings\n\nLonger description."},
+              "helpUri": "https://pkg.go.dev/golang.org/x/tools/go/analysis/passes/printf"
+            },
+            {
+              "id": "custom",
+              "name": "custom"
+            }
+          ]
+        }
+      },
+      "originalUriBaseIds": {
+        "%SRCROOT%": {"uri": "file:///project/"}
+      },
+      "results": [
+        {
+          "ruleId": "printf",
+          "ruleIndex": 0,
+          "level": "error",
+          "message": {"text": "fmt.Printf format %s has arg num of wrong type int"},
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {"uri": "foo.go", "uriBaseId": "%SRCROOT%"},
+                "region": {"startLine": 7, "startColumn": 14, "endLine": 7, "endColumn": 16}
+              }
+            }
+          ],
+          "relatedLocations": [
+            {
+              "id": 0,
+              "physicalLocation": {
+                "artifactLocation": {"uri": "foo.go", "uriBaseId": "%SRCROOT%"},
+                "region": {"startLine": 5, "startColumn": 2, "endLine": 5, "endColumn": 5}
+              },
+              "message": {"text": "num declared here"}
+            }
+          ],
+          "fixes": [
+            {
+              "description": {"text": "use %d"},
+              "artifactChanges": [
+                {
+                  "artifactLocation": {"uri": "foo.go", "uriBaseId": "%SRCROOT%"},
+                  "replacements": [
+                    {
+                      "deletedRegion": {"byteOffset": 60, "byteLength": 2},
+                      "insertedContent": {"text": "%d"}
+                    }
+                  ]
+                }
+              ]
+            }
+          ]
+        },
+        {
+          "ruleId": "custom",
+          "ruleIndex": 1,
+          "level": "warning",
+          "message": {"text": "custom finding"},
+          "locations": [
+            {
+              "physicalLocation": {
+                "artifactLocation": {"uri": "file:///other/bar.go"},
+                "region": {"startLine": 3}
+              }
+            }
+          ]
+        }
+      ]
+    }
+  ]
+}`), &want))
+	assert.Equal(t, want, got)
+}
+
+func TestWriteSARIFInsertionAtStartOfFile(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, report.WriteSARIF(buf, report.Results{Diagnostics: []vetjson.Diagnostic{
+		{
+			Package:  "example.com/foo",
+			Analyzer: "buildtag",
+			Pos:      vetjson.Position{Filename: "/project/foo.go", Line: 1, Column: 1},
+			Message:  "missing build constraint",
+			SuggestedFixes: []vetjson.SuggestedFix{
+				{
+					Message: "add build constraint",
+					Edits: []vetjson.TextEdit{
+						{Filename: "/project/foo.go", Start: 0, End: 0, New: "//go:build linux\n\n"},
+					},
+				},
+			},
+		},
+	}}, nil, "/project"))
+
+	var got struct {
+		Runs []struct {
+			Results []struct {
+				Fixes []struct {
+					ArtifactChanges []struct {
+						Replacements []map[string]any `json:"replacements"`
+					} `json:"artifactChanges"`
+				} `json:"fixes"`
+			} `json:"results"`
+		} `json:"runs"`
+	}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &got))
+	require.Len(t, got.Runs, 1)
+	require.Len(t, got.Runs[0].Results, 1)
+	require.Len(t, got.Runs[0].Results[0].Fixes, 1)
+	require.Len(t, got.Runs[0].Results[0].Fixes[0].ArtifactChanges, 1)
+	// the offset and length of the deleted region are required even if they are 0
+	assert.Equal(t, []map[string]any{
+		{
+			"deletedRegion":   map[string]any{"byteOffset": float64(0), "byteLength": float64(0)},
+			"insertedContent": map[string]any{"text": "//go:build linux\n\n"},
+		},
+	}, got.Runs[0].Results[0].Fixes[0].ArtifactChanges[0].Replacements)
+}
+
+func TestWriteCheckstyle(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, report.WriteCheckstyle(buf, report.Results{
+		Diagnostics: testDiagnostics,
+		Errors: map[string][]okgo.Issue{
+			"example.com/foo/baz": {
+				{Path: "/project/baz/baz.go", Line: 3, Col: 12, Content: "declared and not used: x"},
+			},
+			"": {
+				{Content: "exit status 2"},
+			},
+		},
+	}, "/project"))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<checkstyle version="8.0">
+  <file name="/other/bar.go">
+    <error line="3" severity="warning" message="custom finding" source="govet.custom"></error>
+  </file>
+  <file name="baz/baz.go">
+    <error line="3" column="12" severity="error" message="declared and not used: x" source="govet"></error>
+  </file>
+  <file name="foo.go">
+    <error line="7" column="14" severity="error" message="fmt.Printf format %s has arg num of wrong type int" source="govet.printf"></error>
+    <error line="5" column="2" severity="info" message="num declared here (related to: fmt.Printf format %s has arg num of wrong type int)" source="govet.printf"></error>
+  </file>
+</checkstyle>
+`, buf.String())
+}
+
+func TestWriteJUnit(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, report.WriteJUnit(buf, report.Results{
+		Packages:    []string{"example.com/foo", "example.com/foo/bar", "example.com/foo/clean"},
+		Diagnostics: testDiagnostics,
+		Errors: map[string][]okgo.Issue{
+			"example.com/foo_test": {
+				{Path: "/project/foo_test.go", Line: 3, Col: 12, Content: "declared and not used: x"},
+			},
+			"": {
+				{Content: "exit status 2"},
+			},
+		},
+	}, "/project"))
+	assert.Equal(t, `<?xml version="1.0" encoding="UTF-8"?>
+<testsuites>
+  <testsuite name="govet" tests="4" failures="1" errors="2">
+    <testcase classname="govet" name="example.com/foo">
+      <failure message="1 finding" type="govet">foo.go:7:14: [printf] fmt.Printf format %s has arg num of wrong type int&#xA;&#x9;foo.go:5:2: num declared here</failure>
+      <error message="1 error" type="govet">foo_test.go:3:12: declared and not used: x</error>
+    </testcase>
+    <testcase classname="govet" name="example.com/foo/bar">
+      <system-out>warning: /other/bar.go:3: [custom] custom finding</system-out>
+    </testcase>
+    <testcase classname="govet" name="example.com/foo/clean"></testcase>
+    <testcase classname="govet" name="go vet">
+      <error message="1 error" type="govet">exit status 2</error>
+    </testcase>
+  </testsuite>
+</testsuites>
+`, buf.String())
+}
+
+func TestAnnotationString(t *testing.T) {
+	for i, tc := range []struct {
+		name       string
+		annotation report.Annotation
+		want       string
+	}{
+		{
+			name:       "annotation from diagnostic",
+			annotation: report.AnnotationFromDiagnostic(testDiagnostics[0], "/project"),
+			want:       "::error file=foo.go,line=7,col=14,endLine=7,endColumn=16,title=printf::fmt.Printf format %25s has arg num of wrong type int%0A\tfoo.go:5:2: num declared here",
+		},
+		{
+			name:       "annotation from warning diagnostic outside of base directory",
+			annotation: report.AnnotationFromDiagnostic(testDiagnostics[1], "/project"),
+			want:       "::warning file=/other/bar.go,line=3,title=custom::custom finding",
+		},
+		{
+			name: "annotation from issue",
+			annotation: report.AnnotationFromIssue(okgo.Issue{
+				Path:    "../baz/baz.go",
+				Line:    3,
+				Col:     12,
+				Content: "declared and not used: x",
+			}, "/project/inner", "/project"),
+			want: "::error file=baz/baz.go,line=3,col=12,title=govet::declared and not used: x",
+		},
+		{
+			name: "properties and data are escaped",
+			annotation: report.Annotation{
+				Level:   "warning",
+				Title:   "a,b:c",
+				Message: "line 1\nline 2",
+			},
+			want: "::warning title=a%2Cb%3Ac::line 1%0Aline 2",
+		},
+	} {
+		assert.Equal(t, tc.want, tc.annotation.String(), "Case %d: %s", i, tc.name)
+	}
+}
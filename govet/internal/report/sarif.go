@@ -0,0 +1,315 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package report
+
+import (
+	"encoding/json"
+	"io"
+	"net/url"
+	"path/filepath"
+	"sort"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/analyzers"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/severity"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/vetjson"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+)
+
+const (
+	sarifVersion = "2.1.0"
+	sarifSchema  = "https://json.schemastore.org/sarif-2.1.0.json"
+	// srcRootBaseID is the URI base ID used for all locations in the project directory.
+	srcRootBaseID = "%SRCROOT%"
+)
+
+type sarifLog struct {
+	Version string     `json:"version"`
+	Schema  string     `json:"$schema"`
+	Runs    []sarifRun `json:"runs"`
+}
+
+type sarifRun struct {
+	Tool               sarifTool                        `json:"tool"`
+	OriginalURIBaseIDs map[string]sarifArtifactLocation `json:"originalUriBaseIds,omitempty"`
+	Results            []sarifResult                    `json:"results"`
+}
+
+type sarifTool struct {
+	Driver sarifDriver `json:"driver"`
+}
+
+type sarifDriver struct {
+	Name           string      `json:"name"`
+	InformationURI string      `json:"informationUri"`
+	Rules          []sarifRule `json:"rules"`
+}
+
+type sarifRule struct {
+	ID               string        `json:"id"`
+	Name             string        `json:"name"`
+	ShortDescription *sarifMessage `json:"shortDescription,omitempty"`
+	FullDescription  *sarifMessage `json:"fullDescription,omitempty"`
+	Help             *sarifMessage `json:"help,omitempty"`
+	HelpURI          string        `json:"helpUri,omitempty"`
+}
+
+type sarifMessage struct {
+	Text string `json:"text"`
+}
+
+type sarifResult struct {
+	RuleID           string          `json:"ruleId"`
+	RuleIndex        int             `json:"ruleIndex"`
+	Level            string          `json:"level"`
+	Message          sarifMessage    `json:"message"`
+	Locations        []sarifLocation `json:"locations,omitempty"`
+	RelatedLocations []sarifLocation `json:"relatedLocations,omitempty"`
+	Fixes            []sarifFix      `json:"fixes,omitempty"`
+}
+
+type sarifLocation struct {
+	ID               *int                  `json:"id,omitempty"`
+	PhysicalLocation sarifPhysicalLocation `json:"physicalLocation"`
+	Message          *sarifMessage         `json:"message,omitempty"`
+}
+
+type sarifPhysicalLocation struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Region           *sarifRegion          `json:"region,omitempty"`
+}
+
+type sarifArtifactLocation struct {
+	URI       string `json:"uri"`
+	URIBaseID string `json:"uriBaseId,omitempty"`
+}
+
+type sarifRegion struct {
+	StartLine   int `json:"startLine,omitempty"`
+	StartColumn int `json:"startColumn,omitempty"`
+	EndLine     int `json:"endLine,omitempty"`
+	EndColumn   int `json:"endColumn,omitempty"`
+}
+
+// sarifByteRegion is a region that is specified in bytes. Its fields are always written because an offset or length of
+// 0 is meaningful, such as for an insertion at the beginning of a file.
+type sarifByteRegion struct {
+	ByteOffset int `json:"byteOffset"`
+	ByteLength int `json:"byteLength"`
+}
+
+type sarifFix struct {
+	Description     sarifMessage          `json:"description"`
+	ArtifactChanges []sarifArtifactChange `json:"artifactChanges"`
+}
+
+type sarifArtifactChange struct {
+	ArtifactLocation sarifArtifactLocation `json:"artifactLocation"`
+	Replacements     []sarifReplacement    `json:"replacements"`
+}
+
+type sarifReplacement struct {
+	DeletedRegion   sarifByteRegion `json:"deletedRegion"`
+	InsertedContent *sarifMessage   `json:"insertedContent,omitempty"`
+}
+
+// WriteSARIF writes the provided diagnostics to w as a SARIF 2.1.0 log. The log contains a rule for every provided
+// analyzer and for any other analyzer that reported a diagnostic. The level of every result is derived from the
+// severity of its diagnostic, where the "info" severity corresponds to the "note" level. File locations in projectDir
+// are written relative to the "%SRCROOT%" base URI, which is defined as projectDir.
+func WriteSARIF(w io.Writer, results Results, analyzerList []*analysis.Analyzer, projectDir string) error {
+	rules, ruleIndices := sarifRules(results.Diagnostics, analyzerList)
+	run := sarifRun{
+		Tool: sarifTool{
+			Driver: sarifDriver{
+				Name:           "govet",
+				InformationURI: "https://pkg.go.dev/cmd/vet",
+				Rules:          rules,
+			},
+		},
+		OriginalURIBaseIDs: map[string]sarifArtifactLocation{
+			srcRootBaseID: {
+				URI: fileURI(projectDir, true),
+			},
+		},
+		// results must be non-nil so that it is serialized as an empty array if there are no diagnostics
+		Results: []sarifResult{},
+	}
+	for _, diag := range results.Diagnostics {
+		result := sarifResult{
+			RuleID:    diag.Analyzer,
+			RuleIndex: ruleIndices[diag.Analyzer],
+			Level:     sarifLevel(Severity(diag)),
+			Message:   sarifMessage{Text: diag.Message},
+		}
+		if diag.Pos.IsValid() {
+			result.Locations = append(result.Locations, sarifLocation{
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: artifactLocation(diag.Pos.Filename, projectDir),
+					Region:           positionRegion(diag.Pos, diag.End),
+				},
+			})
+		}
+		for i, related := range diag.Related {
+			if !related.Pos.IsValid() {
+				continue
+			}
+			id := i
+			result.RelatedLocations = append(result.RelatedLocations, sarifLocation{
+				ID: &id,
+				PhysicalLocation: sarifPhysicalLocation{
+					ArtifactLocation: artifactLocation(related.Pos.Filename, projectDir),
+					Region:           positionRegion(related.Pos, related.End),
+				},
+				Message: &sarifMessage{Text: related.Message},
+			})
+		}
+		for _, fix := range diag.SuggestedFixes {
+			result.Fixes = append(result.Fixes, sarifFixFromSuggestedFix(fix, projectDir))
+		}
+		run.Results = append(run.Results, result)
+	}
+
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(sarifLog{
+		Version: sarifVersion,
+		Schema:  sarifSchema,
+		Runs:    []sarifRun{run},
+	}); err != nil {
+		return errors.Wrapf(err, "failed to write SARIF report")
+	}
+	return nil
+}
+
+func sarifRules(diags []vetjson.Diagnostic, analyzerList []*analysis.Analyzer) ([]sarifRule, map[string]int) {
+	var rules []sarifRule
+	ruleIndices := make(map[string]int)
+	for _, analyzer := range analyzerList {
+		if _, ok := ruleIndices[analyzer.Name]; ok {
+			continue
+		}
+		ruleIndices[analyzer.Name] = len(rules)
+		rules = append(rules, sarifRule{
+			ID:               analyzer.Name,
+			Name:             analyzer.Name,
+			ShortDescription: &sarifMessage{Text: analyzers.Title(analyzer)},
+			FullDescription:  &sarifMessage{Text: analyzer.Doc},
+			Help:             &sarifMessage{Text: analyzer.Doc},
+			HelpURI:          analyzer.URL,
+		})
+	}
+	// add rules without metadata for analyzers that reported diagnostics but were not provided
+	var unknown []string
+	for _, diag := range diags {
+		if _, ok := ruleIndices[diag.Analyzer]; ok {
+			continue
+		}
+		ruleIndices[diag.Analyzer] = -1
+		unknown = append(unknown, diag.Analyzer)
+	}
+	sort.Strings(unknown)
+	for _, name := range unknown {
+		ruleIndices[name] = len(rules)
+		rules = append(rules, sarifRule{
+			ID:   name,
+			Name: name,
+		})
+	}
+	return rules, ruleIndices
+}
+
+// sarifLevel returns the SARIF result level for the provided severity.
+func sarifLevel(sev string) string {
+	switch sev {
+	case severity.Warning:
+		return "warning"
+	case severity.Info:
+		return "note"
+	default:
+		return "error"
+	}
+}
+
+// positionRegion returns the region between the provided start and end positions. Go reports columns in bytes while
+// SARIF defaults to UTF-16 code units, so columns are only exact for lines that are entirely ASCII.
+func positionRegion(start, end vetjson.Position) *sarifRegion {
+	region := &sarifRegion{
+		StartLine:   start.Line,
+		StartColumn: start.Column,
+	}
+	if end.IsValid() && end.Filename == start.Filename {
+		region.EndLine = end.Line
+		region.EndColumn = end.Column
+	}
+	return region
+}
+
+func sarifFixFromSuggestedFix(fix vetjson.SuggestedFix, projectDir string) sarifFix {
+	sarifFix := sarifFix{
+		Description: sarifMessage{Text: fix.Message},
+	}
+	changeIndices := make(map[string]int)
+	for _, edit := range fix.Edits {
+		idx, ok := changeIndices[edit.Filename]
+		if !ok {
+			idx = len(sarifFix.ArtifactChanges)
+			changeIndices[edit.Filename] = idx
+			sarifFix.ArtifactChanges = append(sarifFix.ArtifactChanges, sarifArtifactChange{
+				ArtifactLocation: artifactLocation(edit.Filename, projectDir),
+			})
+		}
+		replacement := sarifReplacement{
+			DeletedRegion: sarifByteRegion{
+				ByteOffset: edit.Start,
+				ByteLength: edit.End - edit.Start,
+			},
+		}
+		if edit.New != "" {
+			replacement.InsertedContent = &sarifMessage{Text: edit.New}
+		}
+		sarifFix.ArtifactChanges[idx].Replacements = append(sarifFix.ArtifactChanges[idx].Replacements, replacement)
+	}
+	return sarifFix
+}
+
+// artifactLocation returns the location of the provided file. Files in projectDir are relative to the "%SRCROOT%" base
+// URI, while other files use absolute "file" URIs.
+func artifactLocation(filename, projectDir string) sarifArtifactLocation {
+	if rel, ok := relPath(filename, projectDir); ok {
+		return sarifArtifactLocation{
+			URI:       (&url.URL{Path: filepath.ToSlash(rel)}).EscapedPath(),
+			URIBaseID: srcRootBaseID,
+		}
+	}
+	return sarifArtifactLocation{
+		URI: fileURI(filename, false),
+	}
+}
+
+func fileURI(path string, isDir bool) string {
+	if abs, err := filepath.Abs(path); err == nil {
+		path = abs
+	}
+	path = filepath.ToSlash(path)
+	if isDir && (path == "" || path[len(path)-1] != '/') {
+		path += "/"
+	}
+	if path == "" || path[0] != '/' {
+		// Windows paths such as "C:/foo" require a leading slash
+		path = "/" + path
+	}
+	return (&url.URL{Scheme: "file", Path: path}).String()
+}
@@ -0,0 +1,97 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package severity assigns severities to vet diagnostics.
+package severity
+
+import (
+	"regexp"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// Error is the severity of findings that fail the check. It is the severity of every finding that does not match
+	// a rule.
+	Error = "error"
+	// Warning is the severity of findings that should be addressed but do not fail the check.
+	Warning = "warning"
+	// Info is the severity of findings that are informational.
+	Info = "info"
+)
+
+// Rule assigns a severity to the diagnostics that match it.
+type Rule struct {
+	// Analyzer is the name of the analyzer whose diagnostics match the rule. Diagnostics of all analyzers match if
+	// empty.
+	Analyzer string
+	// Message is a regular expression that matches the messages of the diagnostics that match the rule. Diagnostics
+	// with any message match if empty.
+	Message string
+	// Severity is the severity of the diagnostics that match the rule.
+	Severity string
+}
+
+// Rules are compiled rules that assign severities to diagnostics.
+type Rules []compiledRule
+
+type compiledRule struct {
+	analyzer string
+	message  *regexp.Regexp
+	severity string
+}
+
+// Compile returns the compiled form of the provided rules. Returns an error if a rule has an unsupported severity or
+// an invalid message pattern, or if it matches every diagnostic while not being the last rule.
+func Compile(rules []Rule) (Rules, error) {
+	var compiled Rules
+	for i, rule := range rules {
+		switch rule.Severity {
+		case Error, Warning, Info:
+		default:
+			return nil, errors.Errorf("severity rule %d has unsupported severity %q: must be one of %q, %q or %q", i, rule.Severity, Error, Warning, Info)
+		}
+		if rule.Analyzer == "" && rule.Message == "" && i != len(rules)-1 {
+			return nil, errors.Errorf("severity rule %d matches every diagnostic, so the rules that follow it never match", i)
+		}
+		curr := compiledRule{
+			analyzer: rule.Analyzer,
+			severity: rule.Severity,
+		}
+		if rule.Message != "" {
+			message, err := regexp.Compile(rule.Message)
+			if err != nil {
+				return nil, errors.Wrapf(err, "severity rule %d has invalid message pattern %q", i, rule.Message)
+			}
+			curr.message = message
+		}
+		compiled = append(compiled, curr)
+	}
+	return compiled, nil
+}
+
+// Of returns the severity of a diagnostic with the provided analyzer and message, which is the severity of the first
+// rule that matches it or Error if no rule matches.
+func (r Rules) Of(analyzer, message string) string {
+	for _, rule := range r {
+		if rule.analyzer != "" && rule.analyzer != analyzer {
+			continue
+		}
+		if rule.message != nil && !rule.message.MatchString(message) {
+			continue
+		}
+		return rule.severity
+	}
+	return Error
+}
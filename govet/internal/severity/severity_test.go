@@ -0,0 +1,95 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package severity_test
+
+import (
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/severity"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRulesOf(t *testing.T) {
+	rules, err := severity.Compile([]severity.Rule{
+		{Analyzer: "printf", Message: "^fmt\\.Sprintf call has arguments", Severity: severity.Info},
+		{Analyzer: "shadow", Severity: severity.Warning},
+		{Analyzer: "fieldalignment", Severity: severity.Warning},
+		{Message: "deprecated", Severity: severity.Info},
+	})
+	require.NoError(t, err)
+
+	for i, tc := range []struct {
+		name     string
+		analyzer string
+		message  string
+		want     string
+	}{
+		{"analyzer rule", "shadow", `declaration of "err" shadows declaration at line 5`, severity.Warning},
+		{"analyzer and message rule", "printf", "fmt.Sprintf call has arguments but no formatting directives", severity.Info},
+		{"analyzer rule with other message", "printf", "fmt.Println call has possible Printf formatting directive %d", severity.Error},
+		{"message rule", "stdmethods", "method is deprecated", severity.Info},
+		{"no matching rule", "copylocks", "assignment copies lock value", severity.Error},
+	} {
+		assert.Equal(t, tc.want, rules.Of(tc.analyzer, tc.message), "Case %d: %s", i, tc.name)
+	}
+
+	assert.Equal(t, severity.Error, severity.Rules(nil).Of("shadow", "message"))
+}
+
+func TestCompile(t *testing.T) {
+	for i, tc := range []struct {
+		name      string
+		rules     []severity.Rule
+		wantError string
+	}{
+		{
+			name: "valid rules",
+			rules: []severity.Rule{
+				{Analyzer: "shadow", Severity: severity.Warning},
+				{Severity: severity.Info},
+			},
+		},
+		{
+			name: "unsupported severity",
+			rules: []severity.Rule{
+				{Analyzer: "shadow", Severity: "fatal"},
+			},
+			wantError: `severity rule 0 has unsupported severity "fatal": must be one of "error", "warning" or "info"`,
+		},
+		{
+			name: "invalid message pattern",
+			rules: []severity.Rule{
+				{Analyzer: "shadow", Message: "(", Severity: severity.Warning},
+			},
+			wantError: "severity rule 0 has invalid message pattern \"(\": error parsing regexp: missing closing ): `(`",
+		},
+		{
+			name: "rule that matches everything is not last",
+			rules: []severity.Rule{
+				{Severity: severity.Warning},
+				{Analyzer: "shadow", Severity: severity.Info},
+			},
+			wantError: "severity rule 0 matches every diagnostic, so the rules that follow it never match",
+		},
+	} {
+		_, err := severity.Compile(tc.rules)
+		if tc.wantError != "" {
+			assert.EqualError(t, err, tc.wantError, "Case %d: %s", i, tc.name)
+			continue
+		}
+		assert.NoError(t, err, "Case %d: %s", i, tc.name)
+	}
+}
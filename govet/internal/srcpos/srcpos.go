@@ -0,0 +1,195 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package srcpos maps the positions that "go vet" reports back to the source files from which they originate. "go vet"
+// applies the //line directives of Go files to the positions that it reports, but positions in files that the go
+// command generates while building, such as the output of cgo, and positions in assembly files, to which the assembly
+// analyzers do not apply #line directives, can refer to files that do not exist in the project.
+package srcpos
+
+import (
+	"bufio"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/vetjson"
+)
+
+var (
+	// goLineDirectiveRegexp matches //line directives of the form "//line filename:line" or
+	// "//line filename:line:col". The file name may itself contain colons and is empty if the directive only changes the
+	// line.
+	goLineDirectiveRegexp = regexp.MustCompile(`^//line (.*?):([0-9]+)(?::([0-9]+))?$`)
+	// asmLineDirectiveRegexp matches #line directives of the form `#line line "filename"` or "#line line".
+	asmLineDirectiveRegexp = regexp.MustCompile(`^#line\s+([0-9]+)(?:\s+"([^"]*)")?\s*$`)
+	// cgoFileRegexp matches the names of the files that cgo generates.
+	cgoFileRegexp = regexp.MustCompile(`^(_cgo_.*\.(go|c|h)|.*\.cgo[12]\.(go|c))$`)
+	// buildDirRegexp matches the names of the directories in which the go command writes the files that it generates:
+	// the temporary work directories of builds and the default build cache.
+	buildDirRegexp = regexp.MustCompile(`^go-build[0-9]*$`)
+)
+
+// Resolver resolves the positions that "go vet" reports to positions in source files.
+type Resolver struct {
+	// CacheDir is the absolute path of the build cache directory, which is treated as a directory of generated files.
+	// Only directories named "go-build" are treated as build caches if empty.
+	CacheDir string
+}
+
+// Resolve returns the position in a source file that corresponds to the provided position, which must have an
+// absolute file name. Positions in assembly files are mapped using their #line directives and positions in files that
+// the go command generated while building are mapped using their //line directives. Other positions are returned
+// unchanged. Returns false if the position is in a file that could not be mapped to a source file that exists, such as
+// a generated file without //line directives or one that the go command already removed.
+func (r Resolver) Resolve(pos vetjson.Position) (vetjson.Position, bool) {
+	if !pos.IsValid() {
+		return pos, true
+	}
+	switch {
+	case r.Generated(pos.Filename):
+		mapped, ok := mapPosition(pos, goLineDirective)
+		if !ok || r.Generated(mapped.Filename) || !exists(mapped.Filename) {
+			return pos, false
+		}
+		return mapped, true
+	case filepath.Ext(pos.Filename) == ".s":
+		mapped, ok := mapPosition(pos, asmLineDirective)
+		if !ok {
+			// assembly files without #line directives are source files
+			return pos, true
+		}
+		if !exists(mapped.Filename) {
+			return pos, false
+		}
+		return mapped, true
+	default:
+		return pos, true
+	}
+}
+
+// Generated returns true if the file with the provided absolute path was generated by the go command while building,
+// such as a file generated by cgo or a file in a work directory or the build cache.
+func (r Resolver) Generated(filename string) bool {
+	if cgoFileRegexp.MatchString(filepath.Base(filename)) {
+		return true
+	}
+	if r.CacheDir != "" && strings.HasPrefix(filename, filepath.Clean(r.CacheDir)+string(filepath.Separator)) {
+		return true
+	}
+	for _, dir := range strings.Split(filepath.ToSlash(filepath.Dir(filename)), "/") {
+		if buildDirRegexp.MatchString(dir) {
+			return true
+		}
+	}
+	return false
+}
+
+// lineDirective is a directive that changes the position of the line that follows it.
+type lineDirective struct {
+	// filename is the file name that the directive sets, which is empty if the directive only changes the line.
+	filename string
+	line     int
+	// col is the column that the directive sets for the line that follows it, or 0 if the directive does not set it.
+	col int
+	// unknownColumn is true if the columns of the lines that follow the directive are unknown, which is the case for
+	// //line directives that do not set a column.
+	unknownColumn bool
+}
+
+// goLineDirective parses a //line directive of a Go file. Only directives at the beginning of a line are recognized.
+func goLineDirective(text string) (lineDirective, bool) {
+	match := goLineDirectiveRegexp.FindStringSubmatch(text)
+	if match == nil {
+		return lineDirective{}, false
+	}
+	directive := lineDirective{filename: match[1]}
+	directive.line, _ = strconv.Atoi(match[2])
+	if match[3] != "" {
+		directive.col, _ = strconv.Atoi(match[3])
+	} else {
+		directive.unknownColumn = true
+	}
+	return directive, directive.line > 0
+}
+
+// asmLineDirective parses a #line directive of an assembly file.
+func asmLineDirective(text string) (lineDirective, bool) {
+	match := asmLineDirectiveRegexp.FindStringSubmatch(strings.TrimSpace(text))
+	if match == nil {
+		return lineDirective{}, false
+	}
+	directive := lineDirective{filename: match[2]}
+	directive.line, _ = strconv.Atoi(match[1])
+	return directive, directive.line > 0
+}
+
+// mapPosition returns the position to which the last directive before the provided position maps it. Relative file
+// names in directives are resolved against the directory of the file that contains them. Returns false if the file
+// cannot be read or if no directive precedes the position.
+func mapPosition(pos vetjson.Position, parse func(text string) (lineDirective, bool)) (vetjson.Position, bool) {
+	f, err := os.Open(pos.Filename)
+	if err != nil {
+		return pos, false
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+
+	var (
+		directive     lineDirective
+		directiveLine int
+		filename      string
+	)
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(nil, 1024*1024)
+	for line := 1; line < pos.Line && scanner.Scan(); line++ {
+		current, ok := parse(scanner.Text())
+		if !ok {
+			continue
+		}
+		directive, directiveLine = current, line
+		if current.filename != "" {
+			filename = current.filename
+			if !filepath.IsAbs(filename) {
+				filename = filepath.Join(filepath.Dir(pos.Filename), filename)
+			}
+		}
+	}
+	if scanner.Err() != nil || directiveLine == 0 {
+		return pos, false
+	}
+	if filename == "" {
+		filename = pos.Filename
+	}
+	mapped := vetjson.Position{
+		Filename: filepath.Clean(filename),
+		Line:     directive.line + pos.Line - directiveLine - 1,
+		Column:   pos.Column,
+	}
+	switch {
+	case directive.unknownColumn:
+		mapped.Column = 0
+	case directive.col > 0 && pos.Line == directiveLine+1 && pos.Column > 0:
+		mapped.Column = directive.col + pos.Column - 1
+	}
+	return mapped, true
+}
+
+func exists(filename string) bool {
+	info, err := os.Stat(filename)
+	return err == nil && !info.IsDir()
+}
@@ -0,0 +1,174 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package srcpos_test
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/srcpos"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/vetjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolve(t *testing.T) {
+	for i, tc := range []struct {
+		name string
+		// files maps paths relative to a temporary directory to their contents, in which "$DIR" is replaced by the
+		// directory
+		files    map[string]string
+		cacheDir string
+		pos      vetjson.Position
+		want     vetjson.Position
+		wantOK   bool
+	}{
+		{
+			name:   "position in source file is unchanged",
+			files:  map[string]string{"foo/foo.go": "package foo\n"},
+			pos:    vetjson.Position{Filename: "foo/foo.go", Line: 1, Column: 9},
+			want:   vetjson.Position{Filename: "foo/foo.go", Line: 1, Column: 9},
+			wantOK: true,
+		},
+		{
+			name: "position in cgo output is mapped to the source file",
+			files: map[string]string{
+				"foo/foo.go":                    "package foo\n\nimport \"C\"\n\nfunc foo() {}\n",
+				"go-build1/b001/foo.cgo1.go":    "// Code generated by cmd/cgo; DO NOT EDIT.\n\n//line $DIR/foo/foo.go:1:1\npackage foo\n\nimport _ \"unsafe\"\n\nfunc foo() {}\n",
+				"go-build1/b001/_cgo_import.go": "package foo\n",
+			},
+			pos:    vetjson.Position{Filename: "go-build1/b001/foo.cgo1.go", Line: 8, Column: 6},
+			want:   vetjson.Position{Filename: "foo/foo.go", Line: 5, Column: 6},
+			wantOK: true,
+		},
+		{
+			name: "column of the line that follows a //line directive is offset by the column of the directive",
+			files: map[string]string{
+				"foo/foo.go":                 "package foo\n",
+				"go-build1/b001/foo.cgo1.go": "package foo\n\nfunc foo() {\n//line $DIR/foo/foo.go:3:10\n\tbar()\n}\n",
+			},
+			pos:    vetjson.Position{Filename: "go-build1/b001/foo.cgo1.go", Line: 5, Column: 2},
+			want:   vetjson.Position{Filename: "foo/foo.go", Line: 3, Column: 11},
+			wantOK: true,
+		},
+		{
+			name: "column is unknown after a //line directive without column",
+			files: map[string]string{
+				"foo/foo.go":                 "package foo\n",
+				"go-build1/b001/foo.cgo1.go": "//line $DIR/foo/foo.go:1\npackage foo\n",
+			},
+			pos:    vetjson.Position{Filename: "go-build1/b001/foo.cgo1.go", Line: 2, Column: 9},
+			want:   vetjson.Position{Filename: "foo/foo.go", Line: 1},
+			wantOK: true,
+		},
+		{
+			name: "position in generated file with a line that is too long to be read cannot be mapped",
+			files: map[string]string{
+				"foo/foo.go":                 "package foo\n",
+				"go-build1/b001/foo.cgo1.go": "//line $DIR/foo/foo.go:1:1\n//" + strings.Repeat("x", 1024*1024) + "\npackage foo\n",
+			},
+			pos:    vetjson.Position{Filename: "go-build1/b001/foo.cgo1.go", Line: 3, Column: 9},
+			want:   vetjson.Position{Filename: "go-build1/b001/foo.cgo1.go", Line: 3, Column: 9},
+			wantOK: false,
+		},
+		{
+			name:   "position in generated file without //line directives cannot be mapped",
+			files:  map[string]string{"go-build1/b001/_cgo_gotypes.go": "package foo\n\nvar _ = 1\n"},
+			pos:    vetjson.Position{Filename: "go-build1/b001/_cgo_gotypes.go", Line: 3, Column: 9},
+			want:   vetjson.Position{Filename: "go-build1/b001/_cgo_gotypes.go", Line: 3, Column: 9},
+			wantOK: false,
+		},
+		{
+			name:   "position in removed generated file cannot be mapped",
+			pos:    vetjson.Position{Filename: "go-build1/b001/_cgo_gotypes.go", Line: 3, Column: 9},
+			want:   vetjson.Position{Filename: "go-build1/b001/_cgo_gotypes.go", Line: 3, Column: 9},
+			wantOK: false,
+		},
+		{
+			name: "position in configured build cache cannot be mapped to a file that does not exist",
+			files: map[string]string{
+				"cache/ab/abcdef-d": "//line $DIR/foo/missing.go:1:1\npackage foo\n",
+			},
+			cacheDir: "cache",
+			pos:      vetjson.Position{Filename: "cache/ab/abcdef-d", Line: 2, Column: 1},
+			want:     vetjson.Position{Filename: "cache/ab/abcdef-d", Line: 2, Column: 1},
+			wantOK:   false,
+		},
+		{
+			name: "position in assembly file is mapped using #line directives",
+			files: map[string]string{
+				"foo/add.s.in":    "// template\nTEXT ·add(SB),0,$0-24\n\tRET\n",
+				"foo/add_amd64.s": "// Code generated from add.s.in. DO NOT EDIT.\n\n#line 2 \"add.s.in\"\nTEXT ·add(SB),0,$0-24\n\tRET\n",
+			},
+			pos:    vetjson.Position{Filename: "foo/add_amd64.s", Line: 5, Column: 1},
+			want:   vetjson.Position{Filename: "foo/add.s.in", Line: 3, Column: 1},
+			wantOK: true,
+		},
+		{
+			name:   "position in assembly file without #line directives is unchanged",
+			files:  map[string]string{"foo/add_amd64.s": "TEXT ·add(SB),0,$0-24\n\tRET\n"},
+			pos:    vetjson.Position{Filename: "foo/add_amd64.s", Line: 2, Column: 1},
+			want:   vetjson.Position{Filename: "foo/add_amd64.s", Line: 2, Column: 1},
+			wantOK: true,
+		},
+		{
+			name:   "position in assembly file cannot be mapped to a file that does not exist",
+			files:  map[string]string{"foo/add_amd64.s": "#line 2 \"add.s.in\"\nTEXT ·add(SB),0,$0-24\n\tRET\n"},
+			pos:    vetjson.Position{Filename: "foo/add_amd64.s", Line: 3, Column: 1},
+			want:   vetjson.Position{Filename: "foo/add_amd64.s", Line: 3, Column: 1},
+			wantOK: false,
+		},
+	} {
+		dir := t.TempDir()
+		for path, content := range tc.files {
+			require.NoError(t, os.MkdirAll(filepath.Join(dir, filepath.Dir(path)), 0755), "Case %d: %s", i, tc.name)
+			require.NoError(t, os.WriteFile(filepath.Join(dir, path), []byte(strings.ReplaceAll(content, "$DIR", dir)), 0644), "Case %d: %s", i, tc.name)
+		}
+		resolver := srcpos.Resolver{}
+		if tc.cacheDir != "" {
+			resolver.CacheDir = filepath.Join(dir, tc.cacheDir)
+		}
+		pos := tc.pos
+		pos.Filename = filepath.Join(dir, pos.Filename)
+		want := tc.want
+		want.Filename = filepath.Join(dir, want.Filename)
+
+		got, ok := resolver.Resolve(pos)
+		assert.Equal(t, tc.wantOK, ok, "Case %d: %s", i, tc.name)
+		assert.Equal(t, want, got, "Case %d: %s", i, tc.name)
+	}
+}
+
+func TestGenerated(t *testing.T) {
+	resolver := srcpos.Resolver{CacheDir: "/home/user/cache"}
+	for i, tc := range []struct {
+		filename string
+		want     bool
+	}{
+		{"/project/foo/foo.go", false},
+		{"/project/foo/_cgo_gotypes.go", true},
+		{"/project/foo/foo.cgo1.go", true},
+		{"/project/foo/foo.cgo2.c", true},
+		{"/tmp/go-build123456/b001/foo.go", true},
+		{"/home/user/.cache/go-build/ab/abcdef-d", true},
+		{"/home/user/cache/ab/abcdef-d", true},
+		{"/home/user/cache-other/foo.go", false},
+		{"/project/go-builder/foo.go", false},
+	} {
+		assert.Equal(t, tc.want, resolver.Generated(tc.filename), "Case %d: %s", i, tc.filename)
+	}
+}
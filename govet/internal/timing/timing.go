@@ -0,0 +1,238 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package timing records how long the phases of vetting every package take and writes reports of the recorded
+// timings. Timings are recorded by the vet tool processes that "go vet" runs for every package and are written to
+// files in a shared directory from which the checker reads them once "go vet" completes.
+package timing
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+const (
+	// PhaseLoad is the phase in which the files of a package are read and parsed.
+	PhaseLoad = "load"
+	// PhaseTypeCheck is the phase in which a package is type-checked.
+	PhaseTypeCheck = "type-check"
+	// PhaseAnalyzer is the phase in which an analyzer runs on a package.
+	PhaseAnalyzer = "analyzer"
+)
+
+// Entry is the timing of a single phase of vetting a package.
+type Entry struct {
+	// Package is the ID of the package, such as "foo" or "foo [foo.test]".
+	Package string `json:"package"`
+	// FactsOnly is true if the package was only analyzed to compute the facts that are required to analyze the
+	// packages that import it.
+	FactsOnly bool   `json:"factsOnly,omitempty"`
+	Phase     string `json:"phase"`
+	// Analyzer is the name of the analyzer for entries of the analyzer phase.
+	Analyzer string    `json:"analyzer,omitempty"`
+	Start    time.Time `json:"start"`
+	// Duration is the duration of the phase in nanoseconds.
+	Duration time.Duration `json:"durationNanos"`
+	// Process is the ID of the vet tool process that recorded the entry. Packages are analyzed by separate processes,
+	// so entries with the same process ID are for the same package.
+	Process int `json:"process"`
+}
+
+// Name returns a description of the phase of the entry, such as "type-check" or "analyzer printf".
+func (e Entry) Name() string {
+	if e.Analyzer != "" {
+		return e.Phase + " " + e.Analyzer
+	}
+	return e.Phase
+}
+
+// Recorder records entries for a single package to a file in a directory.
+type Recorder struct {
+	pkg       string
+	factsOnly bool
+	path      string
+}
+
+// NewRecorder returns a recorder that records entries for the package with the provided ID to a file in dir that is
+// named after the current process.
+func NewRecorder(dir, pkg string, factsOnly bool) *Recorder {
+	return &Recorder{
+		pkg:       pkg,
+		factsOnly: factsOnly,
+		path:      filepath.Join(dir, fmt.Sprintf("%d.jsonl", os.Getpid())),
+	}
+}
+
+// Record records that the phase with the provided name started at the provided time and took the provided duration.
+// Entries are appended to the file of the recorder as single writes, so Record is safe for concurrent use. Errors are
+// ignored: timings are best-effort and must not cause the analysis to fail.
+func (r *Recorder) Record(phase, analyzer string, start time.Time, duration time.Duration) {
+	entryBytes, err := json.Marshal(Entry{
+		Package:   r.pkg,
+		FactsOnly: r.factsOnly,
+		Phase:     phase,
+		Analyzer:  analyzer,
+		Start:     start,
+		Duration:  duration,
+		Process:   os.Getpid(),
+	})
+	if err != nil {
+		return
+	}
+	f, err := os.OpenFile(r.path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0644)
+	if err != nil {
+		return
+	}
+	_, _ = f.Write(append(entryBytes, '\n'))
+	_ = f.Close()
+}
+
+// ReadDir returns the entries that were recorded to the files in the provided directory ordered by their start times.
+func ReadDir(dir string) ([]Entry, error) {
+	files, err := filepath.Glob(filepath.Join(dir, "*.jsonl"))
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to list timing files")
+	}
+	var entries []Entry
+	for _, file := range files {
+		fileEntries, err := readFile(file)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, fileEntries...)
+	}
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].Start.Before(entries[j].Start)
+	})
+	return entries, nil
+}
+
+func readFile(path string) ([]Entry, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to open timing file")
+	}
+	defer func() {
+		_ = f.Close()
+	}()
+	var entries []Entry
+	scanner := bufio.NewScanner(f)
+	for scanner.Scan() {
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, errors.Wrapf(err, "failed to decode timing entry in %s", path)
+		}
+		entries = append(entries, entry)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, errors.Wrapf(err, "failed to read timing file %s", path)
+	}
+	return entries, nil
+}
+
+// Slowest returns the n entries with the longest durations ordered from slowest to fastest. Returns all entries if n
+// is not positive or there are fewer than n entries.
+func Slowest(entries []Entry, n int) []Entry {
+	sorted := append([]Entry(nil), entries...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Duration > sorted[j].Duration
+	})
+	if n > 0 && n < len(sorted) {
+		sorted = sorted[:n]
+	}
+	return sorted
+}
+
+// FormatSlowest returns a table of the n slowest of the provided entries preceded by a summary line.
+func FormatSlowest(entries []Entry, n int, total time.Duration) string {
+	slowest := Slowest(entries, n)
+	sb := &strings.Builder{}
+	_, _ = fmt.Fprintf(sb, "govet timing: %s total, %d entries recorded, %d slowest:\n", total.Round(time.Millisecond), len(entries), len(slowest))
+	tw := tabwriter.NewWriter(sb, 0, 0, 2, ' ', 0)
+	for _, entry := range slowest {
+		pkg := entry.Package
+		if entry.FactsOnly {
+			pkg += " (facts only)"
+		}
+		_, _ = fmt.Fprintf(tw, "%12s\t%s\t%s\n", entry.Duration.Round(time.Microsecond), pkg, entry.Name())
+	}
+	_ = tw.Flush()
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+// WriteJSON writes the provided entries to w as a JSON array.
+func WriteJSON(w io.Writer, entries []Entry) error {
+	if entries == nil {
+		entries = []Entry{}
+	}
+	encoder := json.NewEncoder(w)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(entries); err != nil {
+		return errors.Wrapf(err, "failed to write timing report")
+	}
+	return nil
+}
+
+type chromeTrace struct {
+	TraceEvents []chromeTraceEvent `json:"traceEvents"`
+}
+
+type chromeTraceEvent struct {
+	Name string `json:"name"`
+	Cat  string `json:"cat"`
+	Ph   string `json:"ph"`
+	// Ts and Dur are in microseconds.
+	Ts   int64          `json:"ts"`
+	Dur  int64          `json:"dur"`
+	Pid  int            `json:"pid"`
+	Tid  int            `json:"tid"`
+	Args map[string]any `json:"args,omitempty"`
+}
+
+// WriteChromeTrace writes the provided entries to w in the Chrome trace event format, which can be viewed using
+// chrome://tracing or https://ui.perfetto.dev. Every vet tool process is shown as a separate thread.
+func WriteChromeTrace(w io.Writer, entries []Entry) error {
+	trace := chromeTrace{
+		TraceEvents: []chromeTraceEvent{},
+	}
+	for _, entry := range entries {
+		trace.TraceEvents = append(trace.TraceEvents, chromeTraceEvent{
+			Name: entry.Name(),
+			Cat:  entry.Phase,
+			Ph:   "X",
+			Ts:   entry.Start.UnixMicro(),
+			Dur:  entry.Duration.Microseconds(),
+			Pid:  1,
+			Tid:  entry.Process,
+			Args: map[string]any{
+				"package":   entry.Package,
+				"factsOnly": entry.FactsOnly,
+			},
+		})
+	}
+	if err := json.NewEncoder(w).Encode(trace); err != nil {
+		return errors.Wrapf(err, "failed to write Chrome trace")
+	}
+	return nil
+}
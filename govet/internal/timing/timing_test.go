@@ -0,0 +1,77 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timing_test
+
+import (
+	"bytes"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/timing"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var (
+	testStart   = time.Date(2020, 1, 1, 0, 0, 0, 0, time.UTC)
+	testEntries = []timing.Entry{
+		{Package: "example.com/foo", Phase: timing.PhaseLoad, Start: testStart, Duration: 2 * time.Millisecond, Process: 10},
+		{Package: "example.com/foo", Phase: timing.PhaseTypeCheck, Start: testStart.Add(2 * time.Millisecond), Duration: 30 * time.Millisecond, Process: 10},
+		{Package: "example.com/foo", Phase: timing.PhaseAnalyzer, Analyzer: "printf", Start: testStart.Add(32 * time.Millisecond), Duration: 5 * time.Millisecond, Process: 10},
+		{Package: "fmt", FactsOnly: true, Phase: timing.PhaseTypeCheck, Start: testStart, Duration: 50 * time.Millisecond, Process: 11},
+	}
+)
+
+func TestRecordAndReadDir(t *testing.T) {
+	dir := t.TempDir()
+	rec := timing.NewRecorder(dir, "example.com/foo", false)
+	rec.Record(timing.PhaseAnalyzer, "printf", testStart.Add(time.Second), time.Millisecond)
+	rec.Record(timing.PhaseTypeCheck, "", testStart, 2*time.Millisecond)
+
+	entries, err := timing.ReadDir(dir)
+	require.NoError(t, err)
+	pid := os.Getpid()
+	assert.Equal(t, []timing.Entry{
+		{Package: "example.com/foo", Phase: timing.PhaseTypeCheck, Start: testStart, Duration: 2 * time.Millisecond, Process: pid},
+		{Package: "example.com/foo", Phase: timing.PhaseAnalyzer, Analyzer: "printf", Start: testStart.Add(time.Second), Duration: time.Millisecond, Process: pid},
+	}, entries)
+}
+
+func TestFormatSlowest(t *testing.T) {
+	assert.Equal(t, `govet timing: 1.5s total, 4 entries recorded, 3 slowest:
+        50ms  fmt (facts only)  type-check
+        30ms  example.com/foo   type-check
+         5ms  example.com/foo   analyzer printf`, timing.FormatSlowest(testEntries, 3, 1500*time.Millisecond))
+}
+
+func TestWriteChromeTrace(t *testing.T) {
+	buf := &bytes.Buffer{}
+	require.NoError(t, timing.WriteChromeTrace(buf, testEntries[2:3]))
+	assert.JSONEq(t, `{
+  "traceEvents": [
+    {
+      "name": "analyzer printf",
+      "cat": "analyzer",
+      "ph": "X",
+      "ts": 1577836800032000,
+      "dur": 5000,
+      "pid": 1,
+      "tid": 10,
+      "args": {"package": "example.com/foo", "factsOnly": false}
+    }
+  ]
+}`, buf.String())
+}
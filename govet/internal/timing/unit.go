@@ -0,0 +1,113 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package timing
+
+import (
+	"encoding/json"
+	"go/ast"
+	"go/build"
+	"go/importer"
+	"go/parser"
+	"go/token"
+	"go/types"
+	"io"
+	"os"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// unitConfig is the subset of the configuration that "go vet" provides to the vet tool for every package that is
+// required to load and type-check the package.
+type unitConfig struct {
+	ID          string
+	Compiler    string
+	ImportPath  string
+	GoVersion   string
+	GoFiles     []string
+	ImportMap   map[string]string
+	PackageFile map[string]string
+	VetxOnly    bool
+}
+
+// RecordUnit returns a recorder for the package that is described by the provided vet tool configuration file and
+// records the time that it takes to load and type-check the package. The vet tool loads and type-checks the package
+// before running the analyzers, but does not expose how long that takes, so the package is loaded and type-checked
+// again to measure it. This roughly doubles the time that is spent on these phases when timings are recorded.
+func RecordUnit(dir, cfgFile string) (*Recorder, error) {
+	cfgBytes, err := os.ReadFile(cfgFile)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to read vet configuration")
+	}
+	var cfg unitConfig
+	if err := json.Unmarshal(cfgBytes, &cfg); err != nil {
+		return nil, errors.Wrapf(err, "failed to decode vet configuration")
+	}
+	rec := NewRecorder(dir, cfg.ID, cfg.VetxOnly)
+
+	fset := token.NewFileSet()
+	start := time.Now()
+	var files []*ast.File
+	for _, name := range cfg.GoFiles {
+		f, err := parser.ParseFile(fset, name, nil, parser.ParseComments)
+		if err != nil {
+			// the vet tool reports the error
+			return rec, nil
+		}
+		files = append(files, f)
+	}
+	rec.Record(PhaseLoad, "", start, time.Since(start))
+
+	compilerImporter := importer.ForCompiler(fset, cfg.Compiler, func(path string) (io.ReadCloser, error) {
+		file, ok := cfg.PackageFile[path]
+		if !ok {
+			return nil, errors.Errorf("no package file for %q", path)
+		}
+		return os.Open(file)
+	})
+	tc := &types.Config{
+		Importer: importerFunc(func(importPath string) (*types.Package, error) {
+			path, ok := cfg.ImportMap[importPath]
+			if !ok {
+				return nil, errors.Errorf("cannot resolve import %q", importPath)
+			}
+			return compilerImporter.Import(path)
+		}),
+		Sizes:     types.SizesFor("gc", build.Default.GOARCH),
+		GoVersion: cfg.GoVersion,
+		Error:     func(error) {},
+	}
+	// record the same information as the vet tool so that the measurement is representative
+	info := &types.Info{
+		Types:        make(map[ast.Expr]types.TypeAndValue),
+		Defs:         make(map[*ast.Ident]types.Object),
+		Uses:         make(map[*ast.Ident]types.Object),
+		Implicits:    make(map[ast.Node]types.Object),
+		Instances:    make(map[*ast.Ident]types.Instance),
+		Scopes:       make(map[ast.Node]*types.Scope),
+		Selections:   make(map[*ast.SelectorExpr]*types.Selection),
+		FileVersions: make(map[*ast.File]string),
+	}
+	start = time.Now()
+	_, _ = tc.Check(cfg.ImportPath, fset, files, info)
+	rec.Record(PhaseTypeCheck, "", start, time.Since(start))
+	return rec, nil
+}
+
+type importerFunc func(path string) (*types.Package, error)
+
+func (f importerFunc) Import(path string) (*types.Package, error) {
+	return f(path)
+}
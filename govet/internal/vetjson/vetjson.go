@@ -0,0 +1,266 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package vetjson decodes the output of "go vet -json".
+package vetjson
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/pkg/errors"
+)
+
+// Diagnostic is a single diagnostic reported by a vet analyzer.
+type Diagnostic struct {
+	Package        string
+	Analyzer       string
+	Category       string
+	Pos            Position
+	End            Position
+	Message        string
+	SuggestedFixes []SuggestedFix
+	Related        []RelatedInformation
+	// Severity is the severity assigned to the diagnostic by the asset: one of "error", "warning" or "info". It is not
+	// part of the output of "go vet", and the empty string is equivalent to "error".
+	Severity string
+}
+
+// RelatedInformation is a secondary position and message related to a diagnostic, such as the location at which a
+// lock was first copied.
+type RelatedInformation struct {
+	Pos     Position
+	End     Position
+	Message string
+}
+
+// Position is a position in a source file. Line and Column are 1-based, and Column is measured in bytes. Line and
+// Column are 0 if they are unknown.
+type Position struct {
+	Filename string
+	Line     int
+	Column   int
+}
+
+// IsValid returns true if the position refers to a line in a file.
+func (p Position) IsValid() bool {
+	return p.Filename != "" && p.Line > 0
+}
+
+func (p Position) String() string {
+	s := p.Filename
+	if p.Line > 0 {
+		s += fmt.Sprintf(":%d", p.Line)
+		if p.Column > 0 {
+			s += fmt.Sprintf(":%d", p.Column)
+		}
+	}
+	return s
+}
+
+// ParsePosition parses a position of the form "file:line:column", "file:line" or "file". The file name may itself
+// contain colons, so the line and column are parsed from the end of the string.
+func ParsePosition(s string) Position {
+	var pos Position
+	rest := s
+	var nums []int
+	for i := 0; i < 2; i++ {
+		idx := strings.LastIndex(rest, ":")
+		if idx == -1 {
+			break
+		}
+		n, err := strconv.Atoi(rest[idx+1:])
+		if err != nil {
+			break
+		}
+		nums = append([]int{n}, nums...)
+		rest = rest[:idx]
+	}
+	if rest == "-" {
+		// "-" is used for positions that are not known
+		rest = ""
+	}
+	pos.Filename = rest
+	if len(nums) > 0 {
+		pos.Line = nums[0]
+	}
+	if len(nums) > 1 {
+		pos.Column = nums[1]
+	}
+	return pos
+}
+
+// ImportPath returns the import path of the package with the provided ID. "go vet" identifies the variants of a package
+// that are compiled for its tests using IDs such as "foo [foo.test]" or "foo_test [foo.test]": the import path of the
+// package under test is returned for such IDs.
+func ImportPath(id string) string {
+	path, variant, ok := strings.Cut(id, " [")
+	if !ok {
+		return path
+	}
+	if testedPkg, ok := strings.CutSuffix(strings.TrimSuffix(variant, "]"), ".test"); ok {
+		return testedPkg
+	}
+	return path
+}
+
+// SuggestedFix is a fix for a diagnostic that should be applied as a whole or not at all.
+type SuggestedFix struct {
+	Message string
+	Edits   []TextEdit
+}
+
+// TextEdit is the replacement of a portion of a file. Start and End are zero-based half-open byte offsets into the
+// original file.
+type TextEdit struct {
+	Filename string
+	Start    int
+	End      int
+	New      string
+}
+
+// AnalyzerError is an error that prevented an analyzer from running on a package.
+type AnalyzerError struct {
+	Package  string
+	Analyzer string
+	Message  string
+}
+
+func (e AnalyzerError) Error() string {
+	return fmt.Sprintf("%s: analyzer %s failed: %s", e.Package, e.Analyzer, e.Message)
+}
+
+// Decode reads the JSON trees written by "go vet -json" from r and calls handle with the diagnostics and errors in
+// each tree as it is read. "go vet" writes a separate tree for every package that it vets, so handle is typically
+// called once per package. Within a tree, results are ordered by package and then by analyzer name.
+func Decode(r io.Reader, handle func(diags []Diagnostic, errs []AnalyzerError)) error {
+	decoder := json.NewDecoder(r)
+	for {
+		var tree map[string]map[string]json.RawMessage
+		if err := decoder.Decode(&tree); err == io.EOF {
+			return nil
+		} else if err != nil {
+			return errors.Wrapf(err, "failed to decode vet JSON output")
+		}
+		diags, errs, err := fromTree(tree)
+		if err != nil {
+			return err
+		}
+		handle(diags, errs)
+	}
+}
+
+type jsonDiagnostic struct {
+	Category       string             `json:"category"`
+	Posn           string             `json:"posn"`
+	End            string             `json:"end"`
+	Message        string             `json:"message"`
+	SuggestedFixes []jsonSuggestedFix `json:"suggested_fixes"`
+	Related        []jsonRelated      `json:"related"`
+}
+
+type jsonRelated struct {
+	Posn    string `json:"posn"`
+	End     string `json:"end"`
+	Message string `json:"message"`
+}
+
+type jsonSuggestedFix struct {
+	Message string         `json:"message"`
+	Edits   []jsonTextEdit `json:"edits"`
+}
+
+type jsonTextEdit struct {
+	Filename string `json:"filename"`
+	Start    int    `json:"start"`
+	End      int    `json:"end"`
+	New      string `json:"new"`
+}
+
+type jsonError struct {
+	Err string `json:"error"`
+}
+
+func fromTree(tree map[string]map[string]json.RawMessage) ([]Diagnostic, []AnalyzerError, error) {
+	var (
+		diags []Diagnostic
+		errs  []AnalyzerError
+	)
+	for _, pkg := range sortedKeys(tree) {
+		for _, analyzer := range sortedKeys(tree[pkg]) {
+			result := tree[pkg][analyzer]
+			if strings.HasPrefix(strings.TrimSpace(string(result)), "{") {
+				var jsonErr jsonError
+				if err := json.Unmarshal(result, &jsonErr); err != nil {
+					return nil, nil, errors.Wrapf(err, "failed to decode error of analyzer %s for package %s", analyzer, pkg)
+				}
+				errs = append(errs, AnalyzerError{
+					Package:  pkg,
+					Analyzer: analyzer,
+					Message:  jsonErr.Err,
+				})
+				continue
+			}
+			var jsonDiags []jsonDiagnostic
+			if err := json.Unmarshal(result, &jsonDiags); err != nil {
+				return nil, nil, errors.Wrapf(err, "failed to decode diagnostics of analyzer %s for package %s", analyzer, pkg)
+			}
+			for _, jsonDiag := range jsonDiags {
+				diags = append(diags, jsonDiag.toDiagnostic(pkg, analyzer))
+			}
+		}
+	}
+	return diags, errs, nil
+}
+
+func (d jsonDiagnostic) toDiagnostic(pkg, analyzer string) Diagnostic {
+	diag := Diagnostic{
+		Package:  pkg,
+		Analyzer: analyzer,
+		Category: d.Category,
+		Pos:      ParsePosition(d.Posn),
+		End:      ParsePosition(d.End),
+		Message:  d.Message,
+	}
+	for _, fix := range d.SuggestedFixes {
+		suggestedFix := SuggestedFix{
+			Message: fix.Message,
+		}
+		for _, edit := range fix.Edits {
+			suggestedFix.Edits = append(suggestedFix.Edits, TextEdit(edit))
+		}
+		diag.SuggestedFixes = append(diag.SuggestedFixes, suggestedFix)
+	}
+	for _, related := range d.Related {
+		diag.Related = append(diag.Related, RelatedInformation{
+			Pos:     ParsePosition(related.Posn),
+			End:     ParsePosition(related.End),
+			Message: related.Message,
+		})
+	}
+	return diag
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,96 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package vetjson_test
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/vetjson"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDecode(t *testing.T) {
+	const input = `{
+	"example.com/foo": {
+		"copylocks": [
+			{
+				"posn": "/project/foo.go:9:7",
+				"end": "/project/foo.go:9:8",
+				"message": "assignment copies lock value to m2: sync.Mutex",
+				"related": [
+					{
+						"posn": "/project/foo.go:8:2",
+						"end": "/project/foo.go:8:4",
+						"message": "lock declared here"
+					}
+				]
+			}
+		],
+		"unusedresult": {
+			"error": "analysis skipped"
+		}
+	}
+}
+{}
+`
+	var (
+		gotDiags []vetjson.Diagnostic
+		gotErrs  []vetjson.AnalyzerError
+	)
+	require.NoError(t, vetjson.Decode(strings.NewReader(input), func(diags []vetjson.Diagnostic, errs []vetjson.AnalyzerError) {
+		gotDiags = append(gotDiags, diags...)
+		gotErrs = append(gotErrs, errs...)
+	}))
+	assert.Equal(t, []vetjson.Diagnostic{
+		{
+			Package:  "example.com/foo",
+			Analyzer: "copylocks",
+			Pos:      vetjson.Position{Filename: "/project/foo.go", Line: 9, Column: 7},
+			End:      vetjson.Position{Filename: "/project/foo.go", Line: 9, Column: 8},
+			Message:  "assignment copies lock value to m2: sync.Mutex",
+			Related: []vetjson.RelatedInformation{
+				{
+					Pos:     vetjson.Position{Filename: "/project/foo.go", Line: 8, Column: 2},
+					End:     vetjson.Position{Filename: "/project/foo.go", Line: 8, Column: 4},
+					Message: "lock declared here",
+				},
+			},
+		},
+	}, gotDiags)
+	assert.Equal(t, []vetjson.AnalyzerError{
+		{
+			Package:  "example.com/foo",
+			Analyzer: "unusedresult",
+			Message:  "analysis skipped",
+		},
+	}, gotErrs)
+}
+
+func TestParsePosition(t *testing.T) {
+	for i, tc := range []struct {
+		name string
+		in   string
+		want vetjson.Position
+	}{
+		{"file, line and column", "/project/foo.go:9:7", vetjson.Position{Filename: "/project/foo.go", Line: 9, Column: 7}},
+		{"file and line", "/project/foo.go:9", vetjson.Position{Filename: "/project/foo.go", Line: 9}},
+		{"file name with colon", `C:\project\foo.go:9:7`, vetjson.Position{Filename: `C:\project\foo.go`, Line: 9, Column: 7}},
+		{"unknown position", "-", vetjson.Position{}},
+	} {
+		assert.Equal(t, tc.want, vetjson.ParsePosition(tc.in), "Case %d: %s", i, tc.name)
+	}
+}
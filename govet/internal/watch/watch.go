@@ -0,0 +1,229 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+// Package watch provides the building blocks of watch mode: detecting the files that changed, determining the packages
+// that are affected by the changes and computing the difference between successive sets of findings.
+package watch
+
+import (
+	"bytes"
+	"encoding/json"
+	"io"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+)
+
+// FileState is the state of a file that is used to detect changes.
+type FileState struct {
+	ModTime time.Time
+	Size    int64
+}
+
+// Snapshot is the state of a set of files keyed by their absolute paths.
+type Snapshot map[string]FileState
+
+// TakeSnapshot returns the state of the provided directories, of the Go files in them and of the provided additional
+// files. The state of a directory changes when entries are added to or removed from it, which allows new packages to be
+// detected. Directories and files that do not exist are ignored.
+func TakeSnapshot(dirs, files []string) Snapshot {
+	snapshot := make(Snapshot)
+	add := func(path string) {
+		if info, err := os.Stat(path); err == nil {
+			snapshot[path] = FileState{
+				ModTime: info.ModTime(),
+				Size:    info.Size(),
+			}
+		}
+	}
+	for _, dir := range dirs {
+		add(dir)
+		entries, err := os.ReadDir(dir)
+		if err != nil {
+			continue
+		}
+		for _, entry := range entries {
+			if !entry.IsDir() && strings.HasSuffix(entry.Name(), ".go") {
+				add(filepath.Join(dir, entry.Name()))
+			}
+		}
+	}
+	for _, file := range files {
+		add(file)
+	}
+	return snapshot
+}
+
+// ChangedFiles returns the sorted paths of the files that were added, removed or modified between the provided
+// snapshots.
+func ChangedFiles(prev, curr Snapshot) []string {
+	var changed []string
+	for path, state := range curr {
+		if prevState, ok := prev[path]; !ok || !prevState.ModTime.Equal(state.ModTime) || prevState.Size != state.Size {
+			changed = append(changed, path)
+		}
+	}
+	for path := range prev {
+		if _, ok := curr[path]; !ok {
+			changed = append(changed, path)
+		}
+	}
+	sort.Strings(changed)
+	return changed
+}
+
+// Package is a package that is watched.
+type Package struct {
+	ImportPath   string
+	Dir          string
+	Imports      []string
+	TestImports  []string
+	XTestImports []string
+	Module       *struct {
+		GoMod string
+	}
+}
+
+// Graph is the import graph of the watched packages.
+type Graph struct {
+	pkgs []Package
+	// importers maps the import path of a package to the import paths of the watched packages that import it directly,
+	// including from their tests.
+	importers map[string][]string
+}
+
+// LoadGraph returns the import graph of the packages matched by the provided import paths and patterns. The packages
+// are listed using goList, which runs "go list" with the provided arguments and returns its standard output.
+func LoadGraph(goList func(args ...string) ([]byte, error), pkgPaths []string) (*Graph, error) {
+	output, err := goList(append([]string{"-e", "-json=ImportPath,Dir,Imports,TestImports,XTestImports,Module"}, pkgPaths...)...)
+	if err != nil {
+		return nil, err
+	}
+	graph := &Graph{
+		importers: make(map[string][]string),
+	}
+	decoder := json.NewDecoder(bytes.NewReader(output))
+	for {
+		var pkg Package
+		if err := decoder.Decode(&pkg); err == io.EOF {
+			break
+		} else if err != nil {
+			return nil, errors.Wrapf(err, "failed to decode output of go list")
+		}
+		graph.pkgs = append(graph.pkgs, pkg)
+		for _, imports := range [][]string{pkg.Imports, pkg.TestImports, pkg.XTestImports} {
+			for _, imported := range imports {
+				graph.importers[imported] = append(graph.importers[imported], pkg.ImportPath)
+			}
+		}
+	}
+	return graph, nil
+}
+
+// ImportPaths returns the import paths of the watched packages.
+func (g *Graph) ImportPaths() []string {
+	var importPaths []string
+	for _, pkg := range g.pkgs {
+		importPaths = append(importPaths, pkg.ImportPath)
+	}
+	return importPaths
+}
+
+// Dirs returns the directories of the watched packages.
+func (g *Graph) Dirs() []string {
+	var dirs []string
+	for _, pkg := range g.pkgs {
+		if pkg.Dir != "" {
+			dirs = append(dirs, pkg.Dir)
+		}
+	}
+	return dirs
+}
+
+// ModuleFiles returns the go.mod and go.sum files of the modules that contain the watched packages.
+func (g *Graph) ModuleFiles() []string {
+	seen := make(map[string]bool)
+	var files []string
+	for _, pkg := range g.pkgs {
+		if pkg.Module == nil || pkg.Module.GoMod == "" || seen[pkg.Module.GoMod] {
+			continue
+		}
+		seen[pkg.Module.GoMod] = true
+		files = append(files, pkg.Module.GoMod, filepath.Join(filepath.Dir(pkg.Module.GoMod), "go.sum"))
+	}
+	return files
+}
+
+// Affected returns the import paths of the watched packages that are affected by changes to the provided files: the
+// packages in whose directories the files are and the packages that import them, directly or indirectly. All of the
+// watched packages are affected if a go.mod or go.sum file changed.
+func (g *Graph) Affected(files []string) []string {
+	affected := make(map[string]bool)
+	var queue []string
+	for _, file := range files {
+		if base := filepath.Base(file); base == "go.mod" || base == "go.sum" {
+			return g.ImportPaths()
+		}
+		for _, pkg := range g.pkgs {
+			if pkg.Dir == filepath.Dir(file) && !affected[pkg.ImportPath] {
+				affected[pkg.ImportPath] = true
+				queue = append(queue, pkg.ImportPath)
+			}
+		}
+	}
+	for len(queue) > 0 {
+		importPath := queue[0]
+		queue = queue[1:]
+		for _, importer := range g.importers[importPath] {
+			if !affected[importer] {
+				affected[importer] = true
+				queue = append(queue, importer)
+			}
+		}
+	}
+	var importPaths []string
+	for _, importPath := range g.ImportPaths() {
+		if affected[importPath] {
+			importPaths = append(importPaths, importPath)
+		}
+	}
+	return importPaths
+}
+
+// Diff returns the findings in curr that are not in prev and the findings in prev that are not in curr. Findings are
+// compared as multisets, so a finding that occurs more often in curr than in prev is added.
+func Diff(prev, curr []string) (added, resolved []string) {
+	counts := make(map[string]int)
+	for _, finding := range prev {
+		counts[finding]++
+	}
+	for _, finding := range curr {
+		if counts[finding] > 0 {
+			counts[finding]--
+			continue
+		}
+		added = append(added, finding)
+	}
+	for _, finding := range prev {
+		if counts[finding] > 0 {
+			counts[finding]--
+			resolved = append(resolved, finding)
+		}
+	}
+	return added, resolved
+}
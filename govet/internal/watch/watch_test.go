@@ -0,0 +1,122 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package watch_test
+
+import (
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/watch"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangedFiles(t *testing.T) {
+	dir := t.TempDir()
+	writeFiles(t, dir, map[string]string{
+		"a.go":      "package a\n",
+		"b.go":      "package a\n",
+		"notes.txt": "not watched\n",
+	})
+	prev := watch.TakeSnapshot([]string{dir}, nil)
+
+	writeFiles(t, dir, map[string]string{
+		"a.go":      "package a\n\nfunc A() {}\n",
+		"c.go":      "package a\n",
+		"notes.txt": "still not watched\n",
+	})
+	require.NoError(t, os.Remove(filepath.Join(dir, "b.go")))
+	// ensure that the modification time of the directory differs even on file systems with coarse timestamps
+	require.NoError(t, os.Chtimes(dir, time.Time{}, time.Now().Add(time.Minute)))
+
+	assert.Equal(t, []string{
+		dir,
+		filepath.Join(dir, "a.go"),
+		filepath.Join(dir, "b.go"),
+		filepath.Join(dir, "c.go"),
+	}, watch.ChangedFiles(prev, watch.TakeSnapshot([]string{dir}, nil)))
+}
+
+func TestGraphAffected(t *testing.T) {
+	t.Setenv("GOWORK", "off")
+	t.Setenv("GOFLAGS", "-mod=mod")
+
+	projectDir := t.TempDir()
+	writeFiles(t, projectDir, map[string]string{
+		"go.mod":            "module example.com/project\n",
+		"a/a.go":            "package a\n",
+		"b/b.go":            "package b\n\nimport _ \"example.com/project/a\"\n",
+		"c/c.go":            "package c\n",
+		"c/c_test.go":       "package c\n\nimport _ \"example.com/project/b\"\n",
+		"d/d.go":            "package d\n",
+		"d/d_test.go":       "package d_test\n",
+		"d/other/other.txt": "",
+	})
+	t.Chdir(projectDir)
+	graph, err := watch.LoadGraph(goList, []string{"./..."})
+	require.NoError(t, err)
+
+	for i, tc := range []struct {
+		name  string
+		files []string
+		want  []string
+	}{
+		{
+			name:  "importers are affected transitively, including through tests",
+			files: []string{filepath.Join(projectDir, "a", "a.go")},
+			want:  []string{"example.com/project/a", "example.com/project/b", "example.com/project/c"},
+		},
+		{
+			name:  "package without importers",
+			files: []string{filepath.Join(projectDir, "d", "d_test.go")},
+			want:  []string{"example.com/project/d"},
+		},
+		{
+			name:  "go.mod affects all packages",
+			files: []string{filepath.Join(projectDir, "go.mod")},
+			want:  []string{"example.com/project/a", "example.com/project/b", "example.com/project/c", "example.com/project/d"},
+		},
+		{
+			name:  "file outside of packages",
+			files: []string{filepath.Join(projectDir, "d", "other", "other.go")},
+		},
+	} {
+		assert.Equal(t, tc.want, graph.Affected(tc.files), "Case %d: %s", i, tc.name)
+	}
+}
+
+func TestDiff(t *testing.T) {
+	added, resolved := watch.Diff(
+		[]string{"a.go:1:1: [printf] one", "a.go:2:1: [printf] two", "a.go:3:1: [printf] dup"},
+		[]string{"a.go:2:1: [printf] two", "a.go:3:1: [printf] dup", "a.go:3:1: [printf] dup", "b.go:1:1: [shift] new"},
+	)
+	assert.Equal(t, []string{"a.go:3:1: [printf] dup", "b.go:1:1: [shift] new"}, added)
+	assert.Equal(t, []string{"a.go:1:1: [printf] one"}, resolved)
+}
+
+func writeFiles(t *testing.T, dir string, files map[string]string) {
+	for path, content := range files {
+		path = filepath.Join(dir, path)
+		require.NoError(t, os.MkdirAll(filepath.Dir(path), 0755))
+		require.NoError(t, os.WriteFile(path, []byte(content), 0644))
+	}
+}
+
+func goList(args ...string) ([]byte, error) {
+	return exec.Command("go", append([]string{"list"}, args...)...).Output()
+}
@@ -0,0 +1,423 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/analyzers"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/pkgpaths"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/report"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/vetjson"
+	"github.com/palantir/okgo/okgo"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/passes/printf"
+)
+
+// Options configures a run of "go vet" by Run.
+type Options struct {
+	// Packages are the packages that are vetted: import paths, import path patterns such as "./..." or relative or
+	// absolute directories. Relative directories are resolved against Dir.
+	Packages []string
+	// Dir is the directory in which "go vet" is run and relative to which packages are resolved. Defaults to the working
+	// directory of the process.
+	Dir string
+	// EnableAnalyzers are the names of the analyzers that are run in addition to the analyzers that are enabled by
+	// default.
+	EnableAnalyzers []string
+	// DisableAnalyzers are the names of the analyzers that are not run even though they are enabled by default.
+	DisableAnalyzers []string
+	// MatchGoVersion is true if the analyzers that are run for every package are adjusted to the Go version declared by
+	// the go directive of its module. Analyzers that are named in DisableAnalyzers are not adjusted, and vetting fails
+	// with an error if an analyzer that is named in EnableAnalyzers is not run for the Go version of a module.
+	MatchGoVersion bool
+	// PrintfFuncs are the names of the functions that the printf analyzer checks in addition to the functions that it
+	// knows, infers or that are well-known printf wrappers, in the form accepted by its "funcs" flag, such as
+	// "example.com/log.Infof", "(*example.com/log.Logger).Infof" or "Infof".
+	PrintfFuncs []string
+	// Tags are the build tags that are provided to "go vet", which select the files that are vetted.
+	Tags []string
+	// Severities are the rules that assign severities to diagnostics. The severity of a diagnostic is the severity of
+	// the first rule that matches it, or SeverityError if no rule matches.
+	Severities []SeverityRule
+	// ModFallback is the value of the -mod build flag with which "go vet" is run again for packages for which it fails
+	// because the vendor directory is not consistent with go.mod, such as "mod" or "readonly". The vendoring error is
+	// returned if empty or if running "go vet" again fails as well.
+	ModFallback string
+	// Env configures the environment of the go commands that are run, such as "go vet".
+	Env Env
+	// CacheDir is the build cache directory of the go commands that are run, which is provided to them as GOCACHE.
+	// Relative paths are resolved against Dir. The shared build cache is used if empty.
+	CacheDir string
+	// MemoryLimit is the soft memory limit of the go commands that are run and of the vet tool, which is provided to
+	// them as GOMEMLIMIT, such as "2GiB". No limit is set if empty.
+	MemoryLimit string
+	// VetTool is the path to the vet tool that "go vet" uses to run the analyzers, such as the executable of the asset.
+	// Defaults to the executable of the current process, in which case its main function must call VetToolMain if
+	// IsVetToolInvocation returns true before doing anything else. Vetting fails with an error if VetTool is empty and
+	// IsVetToolInvocation was not called, so library callers whose executables do not run as the vet tool must set it.
+	VetTool string
+	// Runner runs the commands, such as "go vet". Defaults to ExecRunner.
+	Runner Runner
+}
+
+// Diagnostic is a finding reported by an analyzer.
+type Diagnostic struct {
+	// Package is the import path of the package for which the diagnostic was reported.
+	Package string
+	// Analyzer is the name of the analyzer that reported the diagnostic.
+	Analyzer string
+	// Category is the category of the diagnostic as reported by the analyzer, if any.
+	Category string
+	// Severity is the severity assigned to the diagnostic by the severity rules: one of SeverityError, SeverityWarning
+	// or SeverityInfo.
+	Severity string
+	// Pos is the position of the diagnostic. It is not valid if the diagnostic was reported at a position that could
+	// not be mapped to a source file.
+	Pos Position
+	// End is the end of the range of the diagnostic. It is not valid if the range is not known.
+	End            Position
+	Message        string
+	SuggestedFixes []SuggestedFix
+	Related        []RelatedInformation
+}
+
+// RelatedInformation is a secondary position and message related to a diagnostic, such as the location at which a
+// lock was first copied.
+type RelatedInformation struct {
+	Pos     Position
+	End     Position
+	Message string
+}
+
+// SuggestedFix is a change that an analyzer suggests to resolve a diagnostic.
+type SuggestedFix struct {
+	Message string
+	Edits   []TextEdit
+}
+
+// TextEdit is the replacement of a portion of a file. Start and End are zero-based half-open byte offsets into the
+// original file.
+type TextEdit struct {
+	Filename string
+	Start    int
+	End      int
+	New      string
+}
+
+// Position is a position in a source file. File names are absolute. Line and Column are 1-based, and Column is
+// measured in bytes. Line and Column are 0 if they are unknown.
+type Position struct {
+	Filename string
+	Line     int
+	Column   int
+}
+
+// IsValid returns true if the position refers to a line in a file.
+func (p Position) IsValid() bool {
+	return vetjson.Position(p).IsValid()
+}
+
+func (p Position) String() string {
+	return vetjson.Position(p).String()
+}
+
+// PackageError is an error that prevented a package from being vetted, such as a type-checking error or a failure of an
+// analyzer, or an error that prevented "go vet" from running.
+type PackageError struct {
+	// Package is the import path of the package that could not be vetted. Empty if the error cannot be attributed to a
+	// package.
+	Package string
+	// Pos is the position of the error. It is not valid if the error does not refer to a file.
+	Pos     Position
+	Message string
+}
+
+func (e PackageError) Error() string {
+	if !e.Pos.IsValid() {
+		return e.Message
+	}
+	return e.Pos.String() + ": " + e.Message
+}
+
+// VetError is the error returned by Run if "go vet" reported errors. The diagnostics of the packages that could be
+// vetted are returned along with it.
+type VetError struct {
+	// Errors are the reported errors sorted by package and position.
+	Errors []PackageError
+}
+
+func (e *VetError) Error() string {
+	if len(e.Errors) == 1 {
+		return e.Errors[0].Error()
+	}
+	lines := []string{fmt.Sprintf("%d errors occurred while vetting:", len(e.Errors))}
+	for _, err := range e.Errors {
+		lines = append(lines, "\t"+strings.ReplaceAll(err.Error(), "\n", "\n\t"))
+	}
+	return strings.Join(lines, "\n")
+}
+
+// Run vets the packages of the provided options and returns the diagnostics of the enabled analyzers sorted by
+// position. Duplicate diagnostics, which test variants of a package can report, are only returned once. If "go vet"
+// reports errors, such as type-checking errors, the diagnostics of the packages that could be vetted are returned along
+// with a *VetError that describes the errors. Running "go vet" is stopped and the error of the context is returned if
+// the provided context is done before it completes.
+func Run(ctx context.Context, opts Options) ([]Diagnostic, error) {
+	if err := opts.validate(); err != nil {
+		return nil, err
+	}
+	if opts.Dir == "" {
+		wd, err := os.Getwd()
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to determine working directory")
+		}
+		opts.Dir = wd
+	} else if !filepath.IsAbs(opts.Dir) {
+		absDir, err := filepath.Abs(opts.Dir)
+		if err != nil {
+			return nil, errors.Wrapf(err, "failed to resolve directory %s", opts.Dir)
+		}
+		opts.Dir = absDir
+	}
+	_, results, err := opts.vet(ctx, &issueWriter{stdout: io.Discard}, nil)
+	if err != nil {
+		return nil, err
+	}
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	var diags []Diagnostic
+	for _, diag := range results.Diagnostics {
+		diags = append(diags, newDiagnostic(diag))
+	}
+	if vetErr := newVetError(results.Errors); vetErr != nil {
+		return diags, vetErr
+	}
+	return diags, nil
+}
+
+// validate returns an error if the options are not valid.
+func (o Options) validate() error {
+	if err := ValidateAnalyzers(o.EnableAnalyzers, o.DisableAnalyzers); err != nil {
+		return err
+	}
+	if err := ValidatePrintfFuncs(o.PrintfFuncs); err != nil {
+		return err
+	}
+	if err := ValidateSeverities(o.Severities); err != nil {
+		return err
+	}
+	if err := ValidateModFallback(o.ModFallback); err != nil {
+		return err
+	}
+	if err := ValidateEnv(o.Env); err != nil {
+		return err
+	}
+	if err := ValidateCacheAndMemoryLimit(o.CacheDir, o.MemoryLimit, o.Env); err != nil {
+		return err
+	}
+	for _, tag := range o.Tags {
+		if tag == "" || strings.ContainsAny(tag, ", \t") {
+			return errors.Errorf("invalid build tag %q", tag)
+		}
+	}
+	return nil
+}
+
+// vet runs "go vet" for the packages of the options in the directory of the options and returns the run and its
+// results. Issues for findings and errors are written to the provided writer as they are reported, and packages that
+// cannot be resolved are written and recorded as errors. If configure is not nil, it is called with the run before
+// "go vet" is run so that the caller can adjust how the run reports its output. Returns a run without packages if no
+// analyzers are enabled or no packages could be resolved, in which case "go vet" is not run. Returns an error if the
+// run cannot be set up.
+func (o Options) vet(ctx context.Context, w *issueWriter, configure func(run *vetRun)) (vetRun, report.Results, error) {
+	results := report.Results{
+		Errors: make(map[string][]okgo.Issue),
+	}
+	analyzerList, vetTool, err := o.analyzersAndVetTool()
+	if err != nil {
+		return vetRun{}, results, err
+	}
+	severities, err := compileSeverities(o.Severities)
+	if err != nil {
+		return vetRun{}, results, err
+	}
+	if len(analyzerList) == 0 {
+		return vetRun{}, results, nil
+	}
+
+	// resolve the provided paths into import paths so that "go vet" accepts them regardless of whether they are
+	// relative, absolute, contain symlinks or contain wildcards
+	pkgPaths, errs := pkgpaths.Resolve(o.Packages, o.Dir, o.getenv)
+	var unresolved []okgo.Issue
+	for _, err := range errs {
+		w.writeError(err)
+		unresolved = append(unresolved, okgo.Issue{Content: err.Error()})
+	}
+	if len(pkgPaths) == 0 {
+		if len(unresolved) > 0 {
+			results.Errors[""] = unresolved
+		}
+		return vetRun{}, results, nil
+	}
+
+	run := vetRun{
+		pkgPaths:    pkgPaths,
+		wd:          o.Dir,
+		vetTool:     vetTool,
+		runner:      o.runner(),
+		positions:   o.positionResolver(),
+		analyzers:   analyzerList,
+		severities:  severities,
+		modFallback: o.ModFallback,
+	}
+	if len(o.Tags) > 0 {
+		run.flags = append(run.flags, "-tags="+strings.Join(o.Tags, ","))
+	}
+	if len(o.PrintfFuncs) > 0 && slices.Contains(analyzerList, printf.Analyzer) {
+		run.flags = append(run.flags, "-"+printf.Analyzer.Name+"."+analyzers.PrintfFuncsFlagName+"="+strings.Join(o.PrintfFuncs, ","))
+	}
+	adjustments, err := o.matchGoVersion(ctx, &run)
+	if err != nil {
+		return vetRun{}, results, err
+	}
+	writeGoVersionAdjustments(adjustments, w)
+	if configure != nil {
+		configure(&run)
+	}
+	results = runVet(ctx, run, w)
+	if len(unresolved) > 0 {
+		results.Errors[""] = append(unresolved, results.Errors[""]...)
+	}
+	return run, results, nil
+}
+
+// analyzersAndVetTool returns the analyzers that are enabled by the options and the vet tool that runs them. Returns no
+// analyzers if none are enabled, in which case there is nothing to run: vet tools run every analyzer if none are
+// selected explicitly.
+func (o Options) analyzersAndVetTool() ([]*analysis.Analyzer, string, error) {
+	analyzerList, err := analyzers.Select(analyzers.All(), o.EnableAnalyzers, o.DisableAnalyzers)
+	if err != nil {
+		return nil, "", err
+	}
+	if o.VetTool != "" {
+		return analyzerList, o.VetTool, nil
+	}
+	// the asset runs the analyzers as the vet tool of "go vet" so that the bundled extras are available, while other
+	// executables would run in place of the vet tool and not report any diagnostics
+	if !vetToolHandled.Load() {
+		return nil, "", errors.Errorf("no vet tool is set and the executable of the current process does not run as the vet tool: set VetTool or call IsVetToolInvocation and VetToolMain at the start of the main function")
+	}
+	vetTool, err := os.Executable()
+	if err != nil {
+		return nil, "", errors.Wrapf(err, "failed to determine path to executable")
+	}
+	return analyzerList, vetTool, nil
+}
+
+// options returns the options that vet the provided packages in wd as configured by the checker. A relative cache
+// directory is resolved against projectDir.
+func (c *Checker) options(pkgPaths []string, wd, projectDir string) Options {
+	cacheDir := c.CacheDir
+	if cacheDir != "" {
+		cacheDir = resolvePath(cacheDir, projectDir)
+	}
+	return Options{
+		Packages:         pkgPaths,
+		Dir:              wd,
+		EnableAnalyzers:  c.EnableAnalyzers,
+		DisableAnalyzers: c.DisableAnalyzers,
+		MatchGoVersion:   c.MatchGoVersion,
+		PrintfFuncs:      c.PrintfFuncs,
+		Severities:       c.Severities,
+		ModFallback:      c.ModFallback,
+		Env:              c.Env,
+		CacheDir:         cacheDir,
+		MemoryLimit:      c.MemoryLimit,
+		VetTool:          c.VetTool,
+		Runner:           c.Runner,
+	}
+}
+
+// newDiagnostic returns the public form of the provided diagnostic.
+func newDiagnostic(diag vetjson.Diagnostic) Diagnostic {
+	converted := Diagnostic{
+		Package:  diag.Package,
+		Analyzer: diag.Analyzer,
+		Category: diag.Category,
+		Severity: report.Severity(diag),
+		Pos:      Position(diag.Pos),
+		End:      Position(diag.End),
+		Message:  diag.Message,
+	}
+	for _, fix := range diag.SuggestedFixes {
+		convertedFix := SuggestedFix{
+			Message: fix.Message,
+		}
+		for _, edit := range fix.Edits {
+			convertedFix.Edits = append(convertedFix.Edits, TextEdit(edit))
+		}
+		converted.SuggestedFixes = append(converted.SuggestedFixes, convertedFix)
+	}
+	for _, related := range diag.Related {
+		converted.Related = append(converted.Related, RelatedInformation{
+			Pos:     Position(related.Pos),
+			End:     Position(related.End),
+			Message: related.Message,
+		})
+	}
+	return converted
+}
+
+// newVetError returns the error for the provided errors, keyed by the import path of the package for which they were
+// reported. Returns nil if there are no errors.
+func newVetError(pkgErrs map[string][]okgo.Issue) *VetError {
+	var errs []PackageError
+	for pkg, issues := range pkgErrs {
+		for _, issue := range issues {
+			errs = append(errs, PackageError{
+				Package: pkg,
+				Pos: Position{
+					Filename: issue.Path,
+					Line:     issue.Line,
+					Column:   issue.Col,
+				},
+				Message: issue.Content,
+			})
+		}
+	}
+	if len(errs) == 0 {
+		return nil
+	}
+	slices.SortStableFunc(errs, func(a, b PackageError) int {
+		return cmp.Or(
+			cmp.Compare(a.Package, b.Package),
+			cmp.Compare(a.Pos.Filename, b.Pos.Filename),
+			cmp.Compare(a.Pos.Line, b.Pos.Line),
+			cmp.Compare(a.Pos.Column, b.Pos.Column),
+			cmp.Compare(a.Message, b.Message),
+		)
+	})
+	return &VetError{Errors: errs}
+}
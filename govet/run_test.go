@@ -0,0 +1,254 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet_test
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRun(t *testing.T) {
+	wd := newModule(t)
+	runner := &fakeRunner{wd: wd}
+	runner.loadFixture(t, "findings")
+
+	diags, err := govet.Run(context.Background(), govet.Options{
+		Packages: []string{"foo/bar", "foo/broken", "foo/tt"},
+		Dir:      wd,
+		Severities: []govet.SeverityRule{
+			{Analyzer: "copylocks", Severity: govet.SeverityWarning},
+		},
+		VetTool: testVetTool,
+		Runner:  runner,
+	})
+
+	barFile := filepath.Join(wd, "bar", "bar.go")
+	assert.Equal(t, []govet.Diagnostic{
+		{
+			Package:  "foo/bar",
+			Analyzer: "printf",
+			Severity: govet.SeverityError,
+			Pos:      govet.Position{Filename: barFile, Line: 11, Column: 14},
+			End:      govet.Position{Filename: barFile, Line: 11, Column: 16},
+			Message:  "fmt.Printf format %s has arg num of wrong type int",
+		},
+		{
+			Package:  "foo/bar",
+			Analyzer: "copylocks",
+			Severity: govet.SeverityWarning,
+			Pos:      govet.Position{Filename: barFile, Line: 13, Column: 8},
+			End:      govet.Position{Filename: barFile, Line: 13, Column: 10},
+			Message:  "assignment copies lock value to m2: sync.Mutex",
+		},
+		{
+			Package:  "foo/bar",
+			Analyzer: "copylocks",
+			Severity: govet.SeverityWarning,
+			Pos:      govet.Position{Filename: barFile, Line: 14, Column: 6},
+			End:      govet.Position{Filename: barFile, Line: 14, Column: 8},
+			Message:  "assignment copies lock value to _: sync.Mutex",
+		},
+		{
+			Package:  "foo/tt",
+			Analyzer: "printf",
+			Severity: govet.SeverityError,
+			Pos:      govet.Position{Filename: filepath.Join(wd, "tt", "a_test.go"), Line: 3, Column: 39},
+			End:      govet.Position{Filename: filepath.Join(wd, "tt", "a_test.go"), Line: 3, Column: 41},
+			Message:  `fmt.Printf format %d has arg "s" of wrong type string`,
+		},
+	}, diags)
+
+	// type errors prevent packages from being vetted, so they are returned as an error along with the diagnostics
+	var vetErr *govet.VetError
+	require.ErrorAs(t, err, &vetErr)
+	assert.Equal(t, []govet.PackageError{
+		{
+			Package: "foo/broken",
+			Pos:     govet.Position{Filename: filepath.Join(wd, "broken", "b.go"), Line: 3, Column: 12},
+			Message: "declared and not used: x",
+		},
+		{
+			Package: "foo/tt_test",
+			Pos:     govet.Position{Filename: filepath.Join(wd, "tt", "b_test.go"), Line: 3, Column: 50},
+			Message: "declared and not used: x",
+		},
+	}, vetErr.Errors)
+	assert.EqualError(t, err, "2 errors occurred while vetting:\n\t"+
+		filepath.Join(wd, "broken", "b.go")+":3:12: declared and not used: x\n\t"+
+		filepath.Join(wd, "tt", "b_test.go")+":3:50: declared and not used: x")
+
+	require.Len(t, runner.cmds, 1)
+	assert.Equal(t, []string{"foo/bar", "foo/broken", "foo/tt"}, runner.cmds[0].Args[len(runner.cmds[0].Args)-3:])
+}
+
+func TestRunCommand(t *testing.T) {
+	wd := newModule(t)
+	runner := &fakeRunner{wd: wd}
+	runner.loadFixture(t, "test-variants")
+
+	diags, err := govet.Run(context.Background(), govet.Options{
+		Packages:        []string{"foo/bar"},
+		Dir:             wd,
+		EnableAnalyzers: []string{"nilness"},
+		Tags:            []string{"integration", "linux"},
+		PrintfFuncs:     []string{"example.com/log.Infof", "(*example.com/log.Logger).Warnf"},
+		Env: govet.Env{
+			Set: map[string]string{"CGO_ENABLED": "0"},
+		},
+		CacheDir: "out/cache",
+		VetTool:  testVetTool,
+		Runner:   runner,
+	})
+	require.NoError(t, err)
+	assert.Len(t, diags, 1)
+
+	require.Len(t, runner.cmds, 1)
+	cmd := runner.cmds[0]
+	assert.Equal(t, []string{"vet", "-vettool=" + testVetTool, "-json", "-tags=integration,linux"}, cmd.Args[:4])
+	assert.Contains(t, cmd.Args, "-nilness")
+	assert.Contains(t, cmd.Args, "-printf.funcs=example.com/log.Infof,(*example.com/log.Logger).Warnf")
+	assert.Equal(t, wd, cmd.Dir)
+	assert.Equal(t, []string{
+		"CGO_ENABLED=0",
+		"GOCACHE=" + filepath.Join(wd, "out", "cache"),
+		"GOVET_ASSET_VETTOOL=1",
+	}, cmd.Env)
+}
+
+func TestRunResolvesPackagesInConfiguredEnv(t *testing.T) {
+	t.Setenv("GOWORK", "")
+	rootDir := t.TempDir()
+	require.NoError(t, os.WriteFile(filepath.Join(rootDir, "go.work"), []byte("go 1.21\n"), 0644))
+	wd := filepath.Join(rootDir, "foo")
+	require.NoError(t, os.Mkdir(wd, 0755))
+	require.NoError(t, os.WriteFile(filepath.Join(wd, "go.mod"), []byte("module foo\n"), 0644))
+	runner := &fakeRunner{wd: wd}
+
+	// the go.work file is ignored because GOWORK is set to "off" for the go commands, so the module is a main module
+	_, err := govet.Run(context.Background(), govet.Options{
+		Packages: []string{"."},
+		Dir:      wd,
+		Env: govet.Env{
+			Set: map[string]string{"GOWORK": "off"},
+		},
+		VetTool: testVetTool,
+		Runner:  runner,
+	})
+	require.NoError(t, err)
+	require.Len(t, runner.cmds, 1)
+	assert.Equal(t, "foo", runner.cmds[0].Args[len(runner.cmds[0].Args)-1])
+}
+
+func TestRunInvalidOptions(t *testing.T) {
+	for i, tc := range []struct {
+		name    string
+		opts    govet.Options
+		wantErr string
+	}{
+		{
+			name: "unknown analyzer",
+			opts: govet.Options{
+				EnableAnalyzers: []string{"unknown"},
+			},
+			wantErr: `unknown analyzer "unknown" in enable list`,
+		},
+		{
+			name: "invalid severity",
+			opts: govet.Options{
+				Severities: []govet.SeverityRule{{Severity: "fatal"}},
+			},
+			wantErr: `severity rule 0 has unsupported severity "fatal": must be one of "error", "warning" or "info"`,
+		},
+		{
+			name: "invalid printf function",
+			opts: govet.Options{
+				PrintfFuncs: []string{"Infof,Warnf"},
+			},
+			wantErr: `invalid printf function "Infof,Warnf": must be a non-empty function name without commas or whitespace`,
+		},
+		{
+			name: "invalid build tag",
+			opts: govet.Options{
+				Tags: []string{"a,b"},
+			},
+			wantErr: `invalid build tag "a,b"`,
+		},
+		{
+			name: "invalid memory limit",
+			opts: govet.Options{
+				MemoryLimit: "2GB",
+			},
+			wantErr: `invalid memory limit "2GB": must be a number of bytes with an optional unit of B, KiB, MiB, GiB or TiB, or "off"`,
+		},
+		{
+			name:    "vet tool not set in an executable that does not run as the vet tool",
+			opts:    govet.Options{},
+			wantErr: "no vet tool is set and the executable of the current process does not run as the vet tool: set VetTool or call IsVetToolInvocation and VetToolMain at the start of the main function",
+		},
+	} {
+		runner := &fakeRunner{}
+		tc.opts.Packages = []string{"foo/bar"}
+		tc.opts.Dir = t.TempDir()
+		tc.opts.Runner = runner
+		_, err := govet.Run(context.Background(), tc.opts)
+		assert.EqualError(t, err, tc.wantErr, "Case %d: %s", i, tc.name)
+		assert.Empty(t, runner.cmds, "Case %d: %s", i, tc.name)
+	}
+}
+
+func TestRunEnabledAnalyzerNotForGoVersion(t *testing.T) {
+	wd := newModule(t)
+	runner := &fakeRunner{
+		wd:     wd,
+		stdout: `{"ImportPath":"foo/bar","Module":{"Path":"foo","GoVersion":"1.22"}}`,
+	}
+
+	_, err := govet.Run(context.Background(), govet.Options{
+		Packages:        []string{"foo/bar"},
+		Dir:             wd,
+		EnableAnalyzers: []string{"loopclosure"},
+		MatchGoVersion:  true,
+		VetTool:         testVetTool,
+		Runner:          runner,
+	})
+	assert.EqualError(t, err, `analyzer "loopclosure" is enabled, but does not apply to module foo with Go version go1.22 because loop variables are scoped per iteration since go1.22: remove it from the enable list or disable match-go-version`)
+	// only the packages were listed
+	require.Len(t, runner.cmds, 1)
+	assert.Equal(t, "list", runner.cmds[0].Args[0])
+}
+
+func TestRunCanceled(t *testing.T) {
+	wd := newModule(t)
+	runner := &fakeRunner{wd: wd}
+	runner.loadFixture(t, "test-variants")
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	diags, err := govet.Run(ctx, govet.Options{
+		Packages: []string{"foo/bar"},
+		Dir:      wd,
+		VetTool:  testVetTool,
+		Runner:   runner,
+	})
+	assert.ErrorIs(t, err, context.Canceled)
+	assert.Nil(t, diags)
+	assert.Empty(t, runner.cmds)
+}
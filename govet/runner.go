@@ -0,0 +1,218 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"syscall"
+
+	"github.com/pkg/errors"
+)
+
+// Command is a command that is run by the checker.
+type Command struct {
+	// Path is the path of the executable. Names that do not contain a path separator are looked up in PATH.
+	Path string
+	// Args are the arguments of the command, not including the name of the executable.
+	Args []string
+	// Dir is the working directory of the command. The command runs in the working directory of the process if empty.
+	Dir string
+	// Env are environment variables of the form "key=value" that are set for the command in addition to the
+	// environment of the process.
+	Env []string
+	// Unset are the names of the environment variables of the process that are not set for the command.
+	Unset []string
+}
+
+// Argv returns the executable followed by the arguments of the command.
+func (c Command) Argv() []string {
+	return append([]string{c.Path}, c.Args...)
+}
+
+// Runner runs the commands of the checker.
+type Runner interface {
+	// Run runs the provided command until it exits, writing its standard output and standard error to the provided
+	// writers, and returns its exit code. The exit code of a command that was terminated by a signal is 128 plus the
+	// number of the signal, as reported by shells. Returns an error if the command could not be run or if the provided
+	// context is done before the command exits, in which case the command is stopped and the exit code is not
+	// meaningful.
+	Run(ctx context.Context, cmd Command, stdout, stderr io.Writer) (int, error)
+}
+
+// ExecRunner is the Runner that runs commands as processes.
+type ExecRunner struct{}
+
+func (ExecRunner) Run(ctx context.Context, cmd Command, stdout, stderr io.Writer) (int, error) {
+	execCmd := exec.CommandContext(ctx, cmd.Path, cmd.Args...)
+	execCmd.Dir = cmd.Dir
+	if len(cmd.Env) > 0 || len(cmd.Unset) > 0 {
+		execCmd.Env = commandEnv(os.Environ(), cmd)
+		// the executable is looked up in the PATH of the command rather than that of the process
+		path, err := lookPath(cmd.Path, environValue(execCmd.Env, "PATH"))
+		if err != nil {
+			return -1, err
+		}
+		execCmd.Path, execCmd.Err = path, nil
+	}
+	execCmd.Stdout = stdout
+	execCmd.Stderr = stderr
+	if err := execCmd.Run(); err != nil {
+		if ctxErr := ctx.Err(); ctxErr != nil {
+			return -1, ctxErr
+		}
+		if exitErr, ok := err.(*exec.ExitError); ok {
+			return exitCode(exitErr), nil
+		}
+		return -1, err
+	}
+	return 0, nil
+}
+
+// exitCode returns the exit code of the process that exited with the provided error. The exit code of a process that
+// was terminated by a signal is 128 plus the number of the signal.
+func exitCode(exitErr *exec.ExitError) int {
+	if status, ok := exitErr.Sys().(syscall.WaitStatus); ok && status.Signaled() {
+		return signalExitCodeBase + int(status.Signal())
+	}
+	return exitErr.ExitCode()
+}
+
+// lookPath returns the path of the executable with the provided name in the directories of the provided value of PATH.
+// Names that contain a path separator are not looked up. Relative directories in PATH are ignored, as they are by
+// exec.LookPath.
+func lookPath(name, pathEnv string) (string, error) {
+	if strings.ContainsRune(name, '/') || strings.ContainsRune(name, filepath.Separator) {
+		return exec.LookPath(name)
+	}
+	for _, dir := range filepath.SplitList(pathEnv) {
+		if !filepath.IsAbs(dir) {
+			continue
+		}
+		if path, err := exec.LookPath(filepath.Join(dir, name)); err == nil {
+			return path, nil
+		}
+	}
+	return "", &exec.Error{Name: name, Err: exec.ErrNotFound}
+}
+
+// environValue returns the value of the variable with the provided name in the provided environment of the form
+// "key=value". The last value takes precedence if the variable is set more than once.
+func environValue(environ []string, name string) string {
+	value := ""
+	for _, kv := range environ {
+		if key, val, ok := strings.Cut(kv, "="); ok && key == name {
+			value = val
+		}
+	}
+	return value
+}
+
+// commandEnv returns the provided environment without the variables that the provided command unsets or sets,
+// followed by the variables that the command sets.
+func commandEnv(environ []string, cmd Command) []string {
+	removed := make(map[string]struct{})
+	for _, name := range cmd.Unset {
+		removed[name] = struct{}{}
+	}
+	for _, kv := range cmd.Env {
+		name, _, _ := strings.Cut(kv, "=")
+		removed[name] = struct{}{}
+	}
+	var env []string
+	for _, kv := range environ {
+		name, _, _ := strings.Cut(kv, "=")
+		if _, ok := removed[name]; !ok {
+			env = append(env, kv)
+		}
+	}
+	return append(env, cmd.Env...)
+}
+
+// runner returns the runner of the options, which is ExecRunner if none is set. The returned runner applies the
+// environment configuration of the options to every command.
+func (o Options) runner() Runner {
+	var runner Runner = ExecRunner{}
+	if o.Runner != nil {
+		runner = o.Runner
+	}
+	env := o.env()
+	if env.empty() {
+		return runner
+	}
+	return envRunner{
+		runner: runner,
+		env:    env,
+	}
+}
+
+// getwd returns the working directory using the working-directory source of the checker.
+func (c *Checker) getwd() (string, error) {
+	getwd := c.Getwd
+	if getwd == nil {
+		getwd = os.Getwd
+	}
+	wd, err := getwd()
+	if err != nil {
+		return "", errors.Wrapf(err, "failed to determine working directory")
+	}
+	return wd, nil
+}
+
+// runOutput runs the provided command using the provided runner and returns its standard output. Returns an error that
+// includes the standard error of the command, if any, if the command could not be run or exited with a non-zero exit
+// code.
+func runOutput(ctx context.Context, runner Runner, cmd Command) ([]byte, error) {
+	stdout, stderr := &bytes.Buffer{}, &bytes.Buffer{}
+	exitCode, err := runner.Run(ctx, cmd, stdout, stderr)
+	if err == nil && exitCode != 0 {
+		err = exitStatusError(exitCode)
+	}
+	if err != nil {
+		if stderrOutput := strings.TrimSpace(stderr.String()); stderrOutput != "" {
+			return nil, errors.Wrapf(err, "failed to run command %v: %s", cmd.Argv(), stderrOutput)
+		}
+		return nil, errors.Wrapf(err, "failed to run command %v", cmd.Argv())
+	}
+	return stdout.Bytes(), nil
+}
+
+const (
+	// signalExitCodeBase is added to the number of the signal that terminated a command to form its exit code.
+	signalExitCodeBase = 128
+	// killedExitCode is the exit code of a command that was killed, which is typically done by the out-of-memory
+	// killer of the operating system.
+	killedExitCode = signalExitCodeBase + int(syscall.SIGKILL)
+)
+
+// exitStatusError returns the error for a command that exited with the provided non-zero exit code.
+func exitStatusError(exitCode int) error {
+	switch {
+	case exitCode == killedExitCode:
+		return errors.Errorf("killed (exit status %d), most likely because the system ran out of memory; %s", exitCode, outOfMemoryHint)
+	case exitCode > signalExitCodeBase:
+		return errors.Errorf("terminated by signal %d (exit status %d)", exitCode-signalExitCodeBase, exitCode)
+	default:
+		return errors.Errorf("exit status %d", exitCode)
+	}
+}
+
+// outOfMemoryHint describes how the memory usage of "go vet" can be reduced.
+const outOfMemoryHint = "set memory-limit so that garbage is collected before memory runs out, run fewer checks in parallel or vet fewer packages at once"
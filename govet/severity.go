@@ -0,0 +1,66 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet
+
+import (
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/analyzers"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/severity"
+	"github.com/pkg/errors"
+)
+
+const (
+	// SeverityError is the severity of findings that fail the check. Findings that do not match a severity rule have
+	// this severity.
+	SeverityError = severity.Error
+	// SeverityWarning is the severity of findings that are included in reports but do not fail the check.
+	SeverityWarning = severity.Warning
+	// SeverityInfo is the severity of informational findings that are included in reports but do not fail the check.
+	SeverityInfo = severity.Info
+)
+
+// SeverityRule assigns a severity to the findings of an analyzer whose messages match a pattern.
+type SeverityRule struct {
+	// Analyzer is the name of the analyzer whose findings match the rule. Findings of all analyzers match if empty.
+	Analyzer string
+	// Message is a regular expression that matches the messages of the findings that match the rule. Findings with any
+	// message match if empty.
+	Message string
+	// Severity is the severity of the findings that match the rule: one of SeverityError, SeverityWarning or
+	// SeverityInfo.
+	Severity string
+}
+
+// ValidateSeverities returns an error if any of the provided rules names an analyzer that is not available, has an
+// unsupported severity or has an invalid message pattern.
+func ValidateSeverities(rules []SeverityRule) error {
+	_, err := compileSeverities(rules)
+	return err
+}
+
+// compileSeverities returns the compiled form of the provided rules.
+func compileSeverities(rules []SeverityRule) (severity.Rules, error) {
+	available := make(map[string]struct{})
+	for _, analyzer := range analyzers.All() {
+		available[analyzer.Name] = struct{}{}
+	}
+	var converted []severity.Rule
+	for i, rule := range rules {
+		if _, ok := available[rule.Analyzer]; rule.Analyzer != "" && !ok {
+			return nil, errors.Errorf("severity rule %d names unknown analyzer %q", i, rule.Analyzer)
+		}
+		converted = append(converted, severity.Rule(rule))
+	}
+	return severity.Compile(converted)
+}
@@ -0,0 +1,730 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet
+
+import (
+	"bufio"
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/limit"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/moderr"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/report"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/severity"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/srcpos"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/vetjson"
+	"github.com/palantir/okgo/okgo"
+	"github.com/pkg/errors"
+	"golang.org/x/tools/go/analysis"
+)
+
+// issueWriter writes okgo issues to an output stream. It is safe for concurrent use.
+type issueWriter struct {
+	mu     sync.Mutex
+	stdout io.Writer
+	// annotations collects a GitHub Actions annotation for every issue that describes a finding. Annotations are not
+	// collected if nil.
+	annotations *bytes.Buffer
+	// annotationBaseDir is the directory relative to which file names in annotations are written.
+	annotationBaseDir string
+	// errorsOnly is true if only diagnostics with the error severity are written.
+	errorsOnly bool
+	// limiter decides whether diagnostics are written. Every diagnostic is written if nil.
+	limiter *limit.Limiter
+	// debug is true if details of the "go vet" invocation and its raw output are written as issues.
+	debug bool
+}
+
+func (w *issueWriter) writeIssue(issue okgo.Issue) {
+	issueJSONBytes, err := json.Marshal(issue)
+	if err != nil {
+		w.writeError(errors.Wrapf(err, "failed to marshal issue %+v as JSON", issue))
+		return
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, _ = fmt.Fprintln(w.stdout, string(issueJSONBytes))
+}
+
+// writeDiagnostic writes the issue for the provided diagnostic and collects its annotation if annotations are enabled.
+// Diagnostics whose severity is not the error severity are not written if errorsOnly is true, and diagnostics that
+// exceed the limits of the limiter are not written.
+func (w *issueWriter) writeDiagnostic(diag vetjson.Diagnostic, wd string) {
+	if w.errorsOnly && report.Severity(diag) != severity.Error {
+		return
+	}
+	if w.limiter != nil && !w.limiter.Allow(diag.Analyzer, vetjson.ImportPath(diag.Package)) {
+		return
+	}
+	w.writeIssue(issueFromDiagnostic(diag, wd))
+	if w.annotations != nil {
+		w.writeAnnotation(report.AnnotationFromDiagnostic(diag, w.annotationBaseDir))
+	}
+}
+
+// writeVetError writes the provided issue, which describes an error reported by "go vet", and collects its annotation
+// if annotations are enabled.
+func (w *issueWriter) writeVetError(issue okgo.Issue, wd string) {
+	w.writeIssue(issue)
+	if w.annotations != nil {
+		w.writeAnnotation(report.AnnotationFromIssue(issue, wd, w.annotationBaseDir))
+	}
+}
+
+// writeAnnotation collects the workflow command of the provided annotation. Annotations are not written as issues
+// because okgo parses all output of the checker, including its standard error, as issues and may prefix the lines of
+// checks that run in parallel, which prevents GitHub Actions from recognizing them.
+func (w *issueWriter) writeAnnotation(annotation report.Annotation) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_, _ = fmt.Fprintln(w.annotations, annotation.String())
+}
+
+func (w *issueWriter) writeError(err error) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	okgo.WriteErrorAsIssue(err, w.stdout)
+}
+
+// vetRun describes a run of "go vet".
+type vetRun struct {
+	pkgPaths []string
+	wd       string
+	// vetTool is the path to the vet tool that runs the analyzers.
+	vetTool string
+	// runner runs "go vet".
+	runner Runner
+	// analyzers are the analyzers that are run for the packages that are not in pkgAnalyzers.
+	analyzers []*analysis.Analyzer
+	// pkgAnalyzers maps the import paths of packages that are vetted with analyzers other than analyzers to the
+	// analyzers that are run for them.
+	pkgAnalyzers map[string][]*analysis.Analyzer
+	// severities assign severities to diagnostics. Every diagnostic has the error severity if empty.
+	severities severity.Rules
+	// flags are additional flags that are provided to "go vet", such as the "-overlay" build flag or flags of the vet
+	// tool.
+	flags []string
+	// replacements maps the paths of files that replace the contents of other files using "-overlay", relative to the
+	// overlay directory that contains them, to the absolute paths of the files that they replace. "go vet" reports
+	// positions in the replacement files, so positions are mapped back to the files that they replace.
+	replacements map[string]string
+	// positions resolves positions in generated and assembly files to positions in the source files from which they
+	// originate.
+	positions srcpos.Resolver
+	// stream is true if findings are written as soon as they are reported rather than in sorted order after all
+	// packages are vetted.
+	stream bool
+	// modFallback is the value of the -mod build flag with which "go vet" is run again after a vendoring error. "go
+	// vet" is not run again if empty.
+	modFallback string
+	// onlyFile is the absolute path of the only file for which diagnostics and errors are reported. Errors that do not
+	// refer to a file are always reported. Diagnostics and errors for all files are reported if empty.
+	onlyFile string
+}
+
+// allAnalyzers returns every analyzer that is run for a package of the run.
+func (r vetRun) allAnalyzers() []*analysis.Analyzer {
+	all := slices.Clone(r.analyzers)
+	for _, pkgPath := range r.pkgPaths {
+		for _, analyzer := range r.pkgAnalyzers[pkgPath] {
+			if !slices.Contains(all, analyzer) {
+				all = append(all, analyzer)
+			}
+		}
+	}
+	return all
+}
+
+// originalPath returns the absolute path of the file whose contents are replaced by the file at the provided absolute
+// path, or the provided path if it does not replace another file. Output that "go vet" replays from its cache refers to
+// the overlay directory of an earlier run, so replacements are matched by their path relative to any overlay directory.
+func (r vetRun) originalPath(path string) string {
+	if len(r.replacements) == 0 {
+		return path
+	}
+	hashDir := filepath.Dir(path)
+	if matched, _ := filepath.Match(overlayDirPattern, filepath.Base(filepath.Dir(hashDir))); !matched {
+		return path
+	}
+	if original, ok := r.replacements[filepath.Join(filepath.Base(hashDir), filepath.Base(path))]; ok {
+		return original
+	}
+	return path
+}
+
+// includes returns true if diagnostics and errors for the file at the provided absolute path should be reported.
+func (r vetRun) includes(path string) bool {
+	return r.onlyFile == "" || path == "" || path == r.onlyFile || canonicalPath(path) == canonicalPath(r.onlyFile)
+}
+
+// canonicalPath returns the provided absolute file path with the symbolic links in its directory evaluated. The file
+// itself does not need to exist.
+func canonicalPath(path string) string {
+	dir, err := filepath.EvalSymlinks(filepath.Dir(path))
+	if err != nil {
+		return path
+	}
+	return filepath.Join(dir, filepath.Base(path))
+}
+
+// findingKey identifies a finding. Test variants of a package and multiple build configurations can report the same
+// finding more than once, so findings with the same key are only reported once.
+type findingKey struct {
+	path     string
+	line     int
+	col      int
+	analyzer string
+	message  string
+}
+
+func (k findingKey) compare(other findingKey) int {
+	return cmp.Or(
+		cmp.Compare(k.path, other.path),
+		cmp.Compare(k.line, other.line),
+		cmp.Compare(k.col, other.col),
+		cmp.Compare(k.analyzer, other.analyzer),
+		cmp.Compare(k.message, other.message),
+	)
+}
+
+func diagnosticKey(diag vetjson.Diagnostic) findingKey {
+	return findingKey{
+		path:     diag.Pos.Filename,
+		line:     diag.Pos.Line,
+		col:      diag.Pos.Column,
+		analyzer: diag.Analyzer,
+		message:  diag.Message,
+	}
+}
+
+func issueKey(issue okgo.Issue) findingKey {
+	return findingKey{
+		path:    issue.Path,
+		line:    issue.Line,
+		col:     issue.Col,
+		message: issue.Content,
+	}
+}
+
+// runVet runs "go vet -json" as described by the provided run and returns the results. Packages that are vetted with
+// different analyzers are vetted by separate invocations of "go vet". Issues for diagnostics and for the output that
+// "go vet" writes to stderr, such as type-checking and build errors, are written to the provided writer. Duplicate
+// findings are dropped, and the remaining findings are written sorted by position after all packages are vetted unless
+// the run streams them, in which case they are written as they are decoded. The diagnostics in the returned results are
+// sorted by position.
+func runVet(ctx context.Context, run vetRun, w *issueWriter) report.Results {
+	out := &vetOutput{
+		stream: run.stream,
+		seen:   make(map[findingKey]struct{}),
+		results: report.Results{
+			Errors: make(map[string][]okgo.Issue),
+		},
+	}
+	for _, group := range run.groups() {
+		if ctx.Err() != nil {
+			break
+		}
+		runVetGroup(ctx, run, group, w, out)
+	}
+	slices.SortStableFunc(out.pending, func(a, b pendingFinding) int {
+		return a.key.compare(b.key)
+	})
+	for _, finding := range out.pending {
+		finding.write()
+	}
+
+	results := out.results
+	for i := range results.Diagnostics {
+		results.Diagnostics[i].Package = vetjson.ImportPath(results.Diagnostics[i].Package)
+	}
+	slices.SortStableFunc(results.Diagnostics, func(a, b vetjson.Diagnostic) int {
+		return diagnosticKey(a).compare(diagnosticKey(b))
+	})
+	return results
+}
+
+// vetOutput collects the findings of the invocations of "go vet" for a run. It is safe for concurrent use.
+type vetOutput struct {
+	stream bool
+
+	mu      sync.Mutex
+	seen    map[findingKey]struct{}
+	pending []pendingFinding
+	results report.Results
+}
+
+// pendingFinding is a finding that is written after all packages are vetted.
+type pendingFinding struct {
+	key   findingKey
+	write func()
+}
+
+// emit writes a finding with the provided key using the provided function, or defers writing it until all packages are
+// vetted if the output does not stream. Returns false if a finding with the same key was already emitted.
+func (o *vetOutput) emit(key findingKey, write func()) bool {
+	o.mu.Lock()
+	if _, ok := o.seen[key]; ok {
+		o.mu.Unlock()
+		return false
+	}
+	o.seen[key] = struct{}{}
+	if !o.stream {
+		o.pending = append(o.pending, pendingFinding{key: key, write: write})
+		o.mu.Unlock()
+		return true
+	}
+	o.mu.Unlock()
+	write()
+	return true
+}
+
+func (o *vetOutput) addDiagnostic(diag vetjson.Diagnostic) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.results.Diagnostics = append(o.results.Diagnostics, diag)
+}
+
+func (o *vetOutput) addError(pkg string, issue okgo.Issue) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.results.Errors[pkg] = append(o.results.Errors[pkg], issue)
+}
+
+// vetGroup is a set of packages that are vetted with the same analyzers.
+type vetGroup struct {
+	pkgPaths  []string
+	analyzers []*analysis.Analyzer
+}
+
+// groups returns the groups of packages of the run that are vetted with the same analyzers in the order in which their
+// first package appears. Packages for which no analyzers are enabled are omitted.
+func (r vetRun) groups() []vetGroup {
+	var groups []vetGroup
+	groupIndices := make(map[string]int)
+	for _, pkgPath := range r.pkgPaths {
+		analyzerList := r.analyzers
+		if pkgAnalyzers, ok := r.pkgAnalyzers[pkgPath]; ok {
+			analyzerList = pkgAnalyzers
+		}
+		if len(analyzerList) == 0 {
+			continue
+		}
+		var names []string
+		for _, analyzer := range analyzerList {
+			names = append(names, analyzer.Name)
+		}
+		key := strings.Join(names, ",")
+		idx, ok := groupIndices[key]
+		if !ok {
+			idx = len(groups)
+			groupIndices[key] = idx
+			groups = append(groups, vetGroup{
+				analyzers: analyzerList,
+			})
+		}
+		groups[idx].pkgPaths = append(groups[idx].pkgPaths, pkgPath)
+	}
+	return groups
+}
+
+// runVetGroup runs "go vet -json" for the provided group of the provided run and adds its findings to out.
+func runVetGroup(ctx context.Context, run vetRun, group vetGroup, w *issueWriter, out *vetOutput) {
+	modErrs, _ := runVetCommand(ctx, run, group, nil, w, out)
+	if run.modFallback != "" && slices.ContainsFunc(modErrs, func(modErr moduleError) bool { return modErr.err.Vendor() }) {
+		// the go command fails before vetting any package if the vendor directory is inconsistent, so running it again
+		// does not report findings twice
+		w.writeDebug("running go vet again with -mod=%s because of vendoring error: %s", run.modFallback, strings.TrimSuffix(modErrs[0].err.Message, ":"))
+		retryModErrs, completed := runVetCommand(ctx, run, group, []string{"-mod=" + run.modFallback}, w, out)
+		if completed && len(retryModErrs) == 0 {
+			modErrs = nil
+		} else {
+			// the vendoring error is the cause of the failure, so it is reported along with the errors of the retry
+			modErrs = append(modErrs, retryModErrs...)
+		}
+	}
+	for _, modErr := range modErrs {
+		writeStderrIssue(run, modErr.pkg, modErr.issue, w, out)
+	}
+}
+
+// moduleError is a module or vendoring error reported by "go vet".
+type moduleError struct {
+	// pkg is the import path of the package for which the error was reported. Empty if the error was not reported
+	// for a package.
+	pkg   string
+	issue okgo.Issue
+	err   *moderr.Error
+}
+
+// runVetCommand runs "go vet" with the provided additional build flags for the packages of the provided group and
+// writes the reported findings and errors. Module and vendoring errors are returned rather than written so that the
+// caller can decide whether to run "go vet" again. Returns false if the command could not be run or failed without
+// describing the failure, such as when it was terminated by a signal.
+func runVetCommand(ctx context.Context, run vetRun, group vetGroup, buildFlags []string, w *issueWriter, out *vetOutput) ([]moduleError, bool) {
+	args := append([]string{"vet", "-vettool=" + run.vetTool, "-json"}, buildFlags...)
+	args = append(args, run.flags...)
+	// enabling analyzers explicitly causes the vet tool to run only those analyzers
+	for _, analyzer := range group.analyzers {
+		args = append(args, "-"+analyzer.Name)
+	}
+	cmd := Command{
+		Path: "go",
+		Args: append(args, group.pkgPaths...),
+		Dir:  run.wd,
+		Env:  []string{vetToolEnvVar + "=1"},
+	}
+	w.writeInvocationDebug(ctx, cmd, run.runner)
+	// the output is processed while the command runs so that issues can be written as soon as they are reported
+	stdoutPipe, stdoutWriter := io.Pipe()
+	stderrPipe, stderrWriter := io.Pipe()
+
+	var (
+		wroteStderr bool
+		modErrs     []moduleError
+		// killedPkgs are the packages for which the vet tool was killed
+		killedPkgs    []string
+		readersDoneWg sync.WaitGroup
+	)
+	readersDoneWg.Add(2)
+	go func() {
+		defer readersDoneWg.Done()
+		var stdout io.Reader = stdoutPipe
+		if w.debug {
+			stdoutDebug := &debugLineWriter{w: w, prefix: "stdout: "}
+			defer stdoutDebug.flush()
+			stdout = io.TeeReader(stdoutPipe, stdoutDebug)
+		}
+		if err := vetjson.Decode(stdout, func(treeDiags []vetjson.Diagnostic, errs []vetjson.AnalyzerError) {
+			for _, diag := range treeDiags {
+				diag = run.resolveDiagnostic(remapDiagnostic(diag, run.originalPath))
+				diag.Severity = run.severities.Of(diag.Analyzer, diag.Message)
+				if !run.includes(diag.Pos.Filename) {
+					continue
+				}
+				if !out.emit(diagnosticKey(diag), func() {
+					w.writeDiagnostic(diag, run.wd)
+				}) {
+					continue
+				}
+				out.addDiagnostic(diag)
+			}
+			for _, err := range errs {
+				issue := okgo.Issue{Content: err.Error()}
+				if !out.emit(issueKey(issue), func() {
+					w.writeError(err)
+				}) {
+					continue
+				}
+				out.addError(vetjson.ImportPath(err.Package), issue)
+			}
+		}); err != nil {
+			w.writeError(err)
+		}
+		// drain any remaining output so that the command does not block
+		_, _ = io.Copy(io.Discard, stdout)
+	}()
+	go func() {
+		defer readersDoneWg.Done()
+		// "go vet" writes a header line of the form "# <package>" before the errors for a package
+		currPkg := ""
+		// errLines are the lines of the error that is being read. An error ends at the first line that does not
+		// continue it.
+		var errLines []string
+		flushErr := func() {
+			if len(errLines) == 0 {
+				return
+			}
+			if pkg, ok := killedVetToolPackage(errLines[0], run.vetTool); ok {
+				wroteStderr = true
+				killedPkgs = append(killedPkgs, pkg)
+				errLines = nil
+				return
+			}
+			issue := issueFromStderrLines(errLines, run.wd, run.vetTool)
+			errLines = nil
+			if issue == (okgo.Issue{}) {
+				return
+			}
+			wroteStderr = true
+			if modErr := moderr.Parse(strings.Split(issue.Content, "\n")); modErr != nil {
+				issue.Content = modErr.Error()
+				modErrs = append(modErrs, moduleError{pkg: currPkg, issue: issue, err: modErr})
+				return
+			}
+			writeStderrIssue(run, currPkg, issue, w, out)
+		}
+		scanner := bufio.NewScanner(stderrPipe)
+		for scanner.Scan() {
+			line := scanner.Text()
+			w.writeDebug("stderr: %s", line)
+			if len(errLines) > 0 && moderr.IsContinuation(line) {
+				errLines = append(errLines, line)
+				continue
+			}
+			flushErr()
+			if pkg, ok := packageFromHeader(line); ok {
+				currPkg = pkg
+				continue
+			}
+			if moderr.IsProgress(line) {
+				continue
+			}
+			errLines = append(errLines, line)
+		}
+		flushErr()
+		if err := scanner.Err(); err != nil {
+			w.writeError(errors.Wrapf(err, "scanner error encountered while reading output"))
+		}
+		// drain any remaining output so that the command does not block
+		_, _ = io.Copy(io.Discard, stderrPipe)
+	}()
+
+	exitCode, err := run.runner.Run(ctx, cmd, stdoutWriter, stderrWriter)
+	_ = stdoutWriter.Close()
+	_ = stderrWriter.Close()
+	readersDoneWg.Wait()
+	if err != nil {
+		w.writeError(errors.Wrapf(err, "failed to run command %v", cmd.Argv()))
+		out.addError("", okgo.Issue{Content: err.Error()})
+		return modErrs, false
+	}
+	w.writeDebug("exit status: %d", exitCode)
+	if len(killedPkgs) > 0 {
+		// the vet tool is run for every package and dependency, so a lack of memory typically kills it many times
+		err := killedVetToolError(killedPkgs)
+		w.writeError(err)
+		out.addError("", okgo.Issue{Content: err.Error()})
+	}
+	// "go vet -json" exits with a zero exit code when analyzers report diagnostics, so a non-zero exit code indicates
+	// that vet itself failed. Such failures are described by the output written to stderr, so only report the exit code
+	// directly if there was no such output or if "go vet" was terminated by a signal, which it cannot describe.
+	if exitCode != 0 && (!wroteStderr || exitCode > signalExitCodeBase) {
+		err := exitStatusError(exitCode)
+		w.writeError(errors.Wrapf(err, "failed to run command %v", cmd.Argv()))
+		out.addError("", okgo.Issue{Content: err.Error()})
+		return modErrs, false
+	}
+	return modErrs, true
+}
+
+// writeStderrIssue writes the provided issue, which "go vet" wrote to stderr for the package with the provided import
+// path, unless it is excluded from the run or was already written.
+func writeStderrIssue(run vetRun, pkg string, issue okgo.Issue, w *issueWriter, out *vetOutput) {
+	// errors are recorded with absolute paths so that reports can make them relative to the project directory
+	if issue.Path != "" {
+		if !filepath.IsAbs(issue.Path) {
+			issue.Path = filepath.Join(run.wd, issue.Path)
+		}
+		issue.Path = run.originalPath(issue.Path)
+		pos, ok := run.positions.Resolve(vetjson.Position{Filename: issue.Path, Line: issue.Line, Column: issue.Col})
+		if ok {
+			issue.Path, issue.Line, issue.Col = pos.Filename, pos.Line, pos.Column
+		} else {
+			issue = okgo.Issue{Content: issue.Content + unmappedPositionNote(pos)}
+		}
+	}
+	if !run.includes(issue.Path) {
+		return
+	}
+	writtenIssue := issue
+	writtenIssue.Path = relToWd(issue.Path, run.wd)
+	if !out.emit(issueKey(issue), func() {
+		w.writeVetError(writtenIssue, run.wd)
+	}) {
+		return
+	}
+	out.addError(pkg, issue)
+}
+
+// resolveDiagnostic returns the provided diagnostic with its positions resolved to positions in source files. If the
+// position of the diagnostic cannot be resolved, the diagnostic is reported without a position and its message notes
+// the position that was reported. Related information that cannot be resolved is reported without a position, and
+// suggested fixes that edit generated files are dropped because their offsets do not apply to the source files.
+func (r vetRun) resolveDiagnostic(diag vetjson.Diagnostic) vetjson.Diagnostic {
+	if pos, ok := r.positions.Resolve(diag.Pos); ok {
+		diag.Pos = pos
+		if end, ok := r.positions.Resolve(diag.End); ok && end.Filename == pos.Filename {
+			diag.End = end
+		} else {
+			diag.End = vetjson.Position{}
+		}
+	} else {
+		diag.Message += unmappedPositionNote(diag.Pos)
+		diag.Pos, diag.End = vetjson.Position{}, vetjson.Position{}
+	}
+	diag.Related = slices.Clone(diag.Related)
+	for i, related := range diag.Related {
+		if pos, ok := r.positions.Resolve(related.Pos); ok {
+			diag.Related[i].Pos = pos
+			if end, ok := r.positions.Resolve(related.End); ok && end.Filename == pos.Filename {
+				diag.Related[i].End = end
+			} else {
+				diag.Related[i].End = vetjson.Position{}
+			}
+		} else {
+			diag.Related[i].Message += unmappedPositionNote(related.Pos)
+			diag.Related[i].Pos, diag.Related[i].End = vetjson.Position{}, vetjson.Position{}
+		}
+	}
+	diag.SuggestedFixes = slices.DeleteFunc(slices.Clone(diag.SuggestedFixes), func(fix vetjson.SuggestedFix) bool {
+		return slices.ContainsFunc(fix.Edits, func(edit vetjson.TextEdit) bool {
+			return r.positions.Generated(edit.Filename)
+		})
+	})
+	return diag
+}
+
+// unmappedPositionNote returns the note that is appended to the message of a finding whose position is in the
+// provided generated file and could not be mapped to a source file. Only the name of the file is included because
+// generated files are typically in temporary directories.
+func unmappedPositionNote(pos vetjson.Position) string {
+	pos.Filename = filepath.Base(pos.Filename)
+	return fmt.Sprintf(" (reported at %s, which could not be mapped to a source file)", pos)
+}
+
+// remapDiagnostic returns the provided diagnostic with the file names of its positions and edits replaced using the
+// provided function.
+func remapDiagnostic(diag vetjson.Diagnostic, remap func(filename string) string) vetjson.Diagnostic {
+	diag.Pos.Filename = remap(diag.Pos.Filename)
+	diag.End.Filename = remap(diag.End.Filename)
+	diag.Related = slices.Clone(diag.Related)
+	for i := range diag.Related {
+		diag.Related[i].Pos.Filename = remap(diag.Related[i].Pos.Filename)
+		diag.Related[i].End.Filename = remap(diag.Related[i].End.Filename)
+	}
+	diag.SuggestedFixes = slices.Clone(diag.SuggestedFixes)
+	for i := range diag.SuggestedFixes {
+		diag.SuggestedFixes[i].Edits = slices.Clone(diag.SuggestedFixes[i].Edits)
+		for j := range diag.SuggestedFixes[i].Edits {
+			diag.SuggestedFixes[i].Edits[j].Filename = remap(diag.SuggestedFixes[i].Edits[j].Filename)
+		}
+	}
+	return diag
+}
+
+// listPackages returns the import paths of the packages matched by the provided patterns in wd.
+func listPackages(ctx context.Context, runner Runner, wd string, pkgPaths []string) ([]string, error) {
+	output, err := runOutput(ctx, runner, Command{
+		Path: "go",
+		Args: append([]string{"list", "-e", "-f", "{{.ImportPath}}"}, pkgPaths...),
+		Dir:  wd,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return strings.Fields(string(output)), nil
+}
+
+// packageFromHeader returns the package for a header line of the form "# <package>". Returns false if the line is not
+// such a header. Header lines that only identify a test variant, such as "# [foo_test]", are not considered headers.
+func packageFromHeader(line string) (string, bool) {
+	if !strings.HasPrefix(line, "# ") {
+		return "", false
+	}
+	pkg := strings.TrimPrefix(line, "# ")
+	if pkg == "" || strings.HasPrefix(pkg, "[") {
+		return "", false
+	}
+	return vetjson.ImportPath(pkg), true
+}
+
+// issueFromDiagnostic returns the okgo issue for the provided diagnostic. The message is prefixed with the severity of
+// the diagnostic unless it is the error severity, and related information is rendered as indented lines that follow the
+// message. Paths are made relative to wd.
+func issueFromDiagnostic(diag vetjson.Diagnostic, wd string) okgo.Issue {
+	content := diag.Message
+	if sev := report.Severity(diag); sev != severity.Error {
+		content = sev + ": " + content
+	}
+	for _, related := range diag.Related {
+		pos := related.Pos
+		pos.Filename = relToWd(pos.Filename, wd)
+		content += "\n\t" + report.RelatedLine(pos, related.Message)
+	}
+	return okgo.Issue{
+		Path:    relToWd(diag.Pos.Filename, wd),
+		Line:    diag.Pos.Line,
+		Col:     diag.Pos.Column,
+		Content: content,
+	}
+}
+
+// issueFromStderrLine returns the okgo issue for a line that "go vet" wrote to stderr. Returns an empty issue for lines
+// that should be ignored.
+func issueFromStderrLine(line, wd, vetTool string) okgo.Issue {
+	// ignore output in the form of comments, which "go vet" uses as headers for the package that produced the output
+	if strings.HasPrefix(line, "#") {
+		return okgo.Issue{}
+	}
+	// errors reported by the vet tool itself, such as type-checking errors, are prefixed with the name of the tool
+	line = strings.TrimPrefix(line, filepath.Base(vetTool)+": ")
+	return okgo.NewIssueFromLine(strings.TrimPrefix(line, "vet: "), wd)
+}
+
+// killedVetToolPackage returns the import path of the package for which the vet tool was killed if the provided line
+// reports that the vet tool was killed. "go vet" reports this in the form "<package>: <vet tool>: signal: killed".
+func killedVetToolPackage(line, vetTool string) (string, bool) {
+	return strings.CutSuffix(line, ": "+vetTool+": signal: killed")
+}
+
+// killedVetToolError returns the error for a vet tool that was killed while vetting the provided packages.
+func killedVetToolError(pkgs []string) error {
+	const maxListed = 3
+	listed := strings.Join(pkgs[:min(len(pkgs), maxListed)], ", ")
+	if len(pkgs) > maxListed {
+		listed += fmt.Sprintf(" and %d more", len(pkgs)-maxListed)
+	}
+	return errors.Errorf("the vet tool was killed while vetting %d package(s) (%s), most likely because the system ran out of memory; %s", len(pkgs), listed, outOfMemoryHint)
+}
+
+// issueFromStderrLines returns the issue for an error that "go vet" wrote to stderr over the provided lines, which
+// consist of a line that describes the error and the indented lines that continue it. The continuation lines are
+// appended to the content of the issue.
+func issueFromStderrLines(lines []string, wd, vetTool string) okgo.Issue {
+	issue := issueFromStderrLine(lines[0], wd, vetTool)
+	if issue == (okgo.Issue{}) {
+		return issue
+	}
+	contentLines := []string{issue.Content}
+	for _, line := range lines[1:] {
+		contentLines = append(contentLines, strings.TrimRight(line, " \t"))
+	}
+	issue.Content = strings.TrimRight(strings.Join(contentLines, "\n"), "\n")
+	return issue
+}
+
+// relToWd returns the provided path relative to wd if it is absolute. Files in wd itself are prefixed with "./", which
+// matches the paths that "go vet" prints for them.
+func relToWd(path, wd string) string {
+	if !filepath.IsAbs(path) {
+		return path
+	}
+	relPath, err := filepath.Rel(wd, path)
+	if err != nil {
+		return path
+	}
+	if filepath.Dir(relPath) == "." {
+		return "." + string(filepath.Separator) + relPath
+	}
+	return relPath
+}
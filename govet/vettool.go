@@ -0,0 +1,124 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/analyzers"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/timing"
+	"golang.org/x/tools/go/analysis"
+	"golang.org/x/tools/go/analysis/unitchecker"
+)
+
+const (
+	// vetToolEnvVar is set in the environment of "go vet" when the checker runs it with the asset as its vet tool.
+	// "go vet" runs the vet tool with the same environment, which allows the asset to detect that it was invoked as a
+	// vet tool.
+	vetToolEnvVar = "GOVET_ASSET_VETTOOL"
+
+	// timingDirFlagName is the name of the vet tool flag that enables timing. The vet tool records timings to files in
+	// the directory that is the value of the flag. "go vet" includes the flags of the vet tool in the keys of its
+	// cache, so providing a new directory for every run ensures that no results are replayed from the cache.
+	timingDirFlagName = "timing-dir"
+)
+
+// vetToolHandled is true if IsVetToolInvocation was called, which indicates that the executable of the current process
+// runs as the vet tool when "go vet" invokes it.
+var vetToolHandled atomic.Bool
+
+// IsVetToolInvocation returns true if the current process was invoked by "go vet" as its vet tool. Calling it indicates
+// that the executable of the current process calls VetToolMain if it returns true, which allows the executable to be
+// used as the default vet tool.
+func IsVetToolInvocation() bool {
+	vetToolHandled.Store(true)
+	return os.Getenv(vetToolEnvVar) != ""
+}
+
+// VetToolMain runs the current process as a vet tool that provides every available analyzer, including the registered
+// analyzers. The printf analyzer checks the well-known printf wrappers in addition to the functions that it knows or
+// infers. Analyzers are selected using the flags that "go vet" provides. Does not return.
+func VetToolMain() {
+	timingDir := flag.String(timingDirFlagName, "", "directory to which the timings of the analysis are recorded")
+	if err := analyzers.RecognizePrintfWrappers(); err != nil {
+		_, _ = fmt.Fprintln(os.Stderr, err)
+		os.Exit(1)
+	}
+	analyzerList := analyzers.All()
+	instrument(analyzerList, func() *timing.Recorder {
+		if *timingDir == "" {
+			return nil
+		}
+		// the configuration file that describes the package is the last argument
+		cfgFile := os.Args[len(os.Args)-1]
+		if !strings.HasSuffix(cfgFile, ".cfg") {
+			return nil
+		}
+		rec, err := timing.RecordUnit(*timingDir, cfgFile)
+		if err != nil {
+			return nil
+		}
+		return rec
+	})
+	unitchecker.Main(analyzerList...)
+}
+
+// instrument replaces the run functions of the provided analyzers and the analyzers that they require with functions
+// that record how long every analyzer takes using the recorder returned by newRecorder. newRecorder is called once,
+// when the first analyzer runs. Timings are not recorded if it returns nil. The analyzers are modified in place, so
+// instrument must only be called by processes that are dedicated to running the analyzers.
+func instrument(analyzerList []*analysis.Analyzer, newRecorder func() *timing.Recorder) {
+	var (
+		once sync.Once
+		rec  *timing.Recorder
+	)
+	recorder := func() *timing.Recorder {
+		once.Do(func() {
+			rec = newRecorder()
+		})
+		return rec
+	}
+	seen := make(map[*analysis.Analyzer]bool)
+	var visit func(analyzer *analysis.Analyzer)
+	visit = func(analyzer *analysis.Analyzer) {
+		if seen[analyzer] {
+			return
+		}
+		seen[analyzer] = true
+		for _, required := range analyzer.Requires {
+			visit(required)
+		}
+		run := analyzer.Run
+		analyzer.Run = func(pass *analysis.Pass) (any, error) {
+			rec := recorder()
+			if rec == nil {
+				return run(pass)
+			}
+			start := time.Now()
+			result, err := run(pass)
+			rec.Record(timing.PhaseAnalyzer, analyzer.Name, start, time.Since(start))
+			return result, err
+		}
+	}
+	for _, analyzer := range analyzerList {
+		visit(analyzer)
+	}
+}
@@ -0,0 +1,173 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"slices"
+	"time"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/pkgpaths"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/report"
+	"github.com/palantir/godel-okgo-asset-govet/govet/internal/watch"
+	"github.com/pkg/errors"
+)
+
+// Watch vets the provided packages and then polls their files at the provided interval, re-vetting the packages that
+// are affected by every change until ctx is done. The packages that are affected by a change are the packages that
+// contain the changed files and the watched packages that import them. Packages that are added in a new directory are
+// detected if the parent directory is the directory of a watched package. After every run, the findings that were added
+// and resolved since the previous run are written to stdout as lines that start with "+" and "-", respectively.
+// Packages are resolved and vetted in the same manner as Check. The interval must be positive.
+func (c *Checker) Watch(ctx context.Context, pkgPaths []string, interval time.Duration, stdout io.Writer) error {
+	if interval <= 0 {
+		return errors.Errorf("watch interval must be positive, was %s", interval)
+	}
+	wd, err := c.getwd()
+	if err != nil {
+		return err
+	}
+	opts := c.options(nil, wd, wd)
+	analyzerList, _, err := opts.analyzersAndVetTool()
+	if err != nil {
+		return err
+	}
+	if len(analyzerList) == 0 {
+		return errors.Errorf("no analyzers are enabled")
+	}
+	if _, err := compileSeverities(opts.Severities); err != nil {
+		return err
+	}
+	pkgPaths, errs := pkgpaths.Resolve(pkgPaths, wd, opts.getenv)
+	for _, err := range errs {
+		_, _ = fmt.Fprintln(stdout, err)
+	}
+	if len(pkgPaths) == 0 {
+		return errors.Errorf("no packages to watch")
+	}
+
+	runner := opts.runner()
+	goList := func(args ...string) ([]byte, error) {
+		return runOutput(ctx, runner, Command{
+			Path: "go",
+			Args: append([]string{"list"}, args...),
+			Dir:  wd,
+		})
+	}
+	graph, err := watch.LoadGraph(goList, pkgPaths)
+	if err != nil {
+		return err
+	}
+	snapshot := watch.TakeSnapshot(graph.Dirs(), graph.ModuleFiles())
+	// findings maps the import path of every vetted package to its findings. Findings that cannot be attributed to a
+	// package are keyed by the empty string.
+	findings := make(map[string][]string)
+	// vet vets the packages with the provided import paths and drops the findings of the removed packages
+	vet := func(importPaths, removed []string) {
+		start := time.Now()
+		currFindings := make(map[string][]string)
+		if len(importPaths) > 0 {
+			vetOpts := opts
+			vetOpts.Packages = importPaths
+			_, results, err := vetOpts.vet(ctx, &issueWriter{stdout: io.Discard}, nil)
+			if err != nil {
+				// the packages were not vetted, so their findings are kept
+				_, _ = fmt.Fprintln(stdout, err)
+				return
+			}
+			currFindings = findingsByPackage(results, wd)
+		}
+
+		var prev, curr []string
+		for _, importPath := range slices.Concat(importPaths, removed, []string{""}) {
+			prev = append(prev, findings[importPath]...)
+			curr = append(curr, currFindings[importPath]...)
+			findings[importPath] = currFindings[importPath]
+		}
+		added, resolved := watch.Diff(prev, curr)
+		total := 0
+		for _, pkgFindings := range findings {
+			total += len(pkgFindings)
+		}
+		_, _ = fmt.Fprintf(stdout, "[%s] vetted %d packages in %s: %d new, %d resolved, %d total\n",
+			time.Now().Format(time.TimeOnly), len(importPaths), time.Since(start).Round(time.Millisecond), len(added), len(resolved), total)
+		for _, finding := range added {
+			_, _ = fmt.Fprintln(stdout, "+ "+finding)
+		}
+		for _, finding := range resolved {
+			_, _ = fmt.Fprintln(stdout, "- "+finding)
+		}
+	}
+	vet(graph.ImportPaths(), nil)
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return nil
+		case <-ticker.C:
+		}
+		currSnapshot := watch.TakeSnapshot(graph.Dirs(), graph.ModuleFiles())
+		changed := watch.ChangedFiles(snapshot, currSnapshot)
+		if len(changed) == 0 {
+			continue
+		}
+		// the changes may have added or removed packages and imports, so the graph is reloaded
+		currGraph, err := watch.LoadGraph(goList, pkgPaths)
+		if err != nil {
+			_, _ = fmt.Fprintln(stdout, err)
+			continue
+		}
+		affected := currGraph.Affected(changed)
+		// packages that were added are affected even if they are in directories that were not watched
+		for _, importPath := range currGraph.ImportPaths() {
+			if !slices.Contains(graph.ImportPaths(), importPath) && !slices.Contains(affected, importPath) {
+				affected = append(affected, importPath)
+			}
+		}
+		var removed []string
+		for _, importPath := range graph.ImportPaths() {
+			if !slices.Contains(currGraph.ImportPaths(), importPath) {
+				removed = append(removed, importPath)
+			}
+		}
+		graph = currGraph
+		snapshot = watch.TakeSnapshot(graph.Dirs(), graph.ModuleFiles())
+		if len(affected) > 0 || len(removed) > 0 {
+			vet(affected, removed)
+		}
+	}
+}
+
+// findingsByPackage returns the text of every diagnostic and error in the provided results keyed by the import path of
+// its package. Paths are made relative to wd.
+func findingsByPackage(results report.Results, wd string) map[string][]string {
+	findings := make(map[string][]string)
+	for _, diag := range results.Diagnostics {
+		issue := issueFromDiagnostic(diag, wd)
+		issue.Content = fmt.Sprintf("[%s] %s", diag.Analyzer, issue.Content)
+		findings[diag.Package] = append(findings[diag.Package], issue.String())
+	}
+	for pkg, issues := range results.Errors {
+		for _, issue := range issues {
+			issue.Path = relToWd(issue.Path, wd)
+			findings[pkg] = append(findings[pkg], issue.String())
+		}
+	}
+	return findings
+}
@@ -0,0 +1,60 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package govet_test
+
+import (
+	"bytes"
+	"context"
+	"testing"
+	"time"
+
+	"github.com/palantir/godel-okgo-asset-govet/govet"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestWatchInvalidInterval(t *testing.T) {
+	for i, tc := range []struct {
+		name     string
+		interval time.Duration
+		wantErr  string
+	}{
+		{
+			name:     "zero interval",
+			interval: 0,
+			wantErr:  "watch interval must be positive, was 0s",
+		},
+		{
+			name:     "negative interval",
+			interval: -time.Second,
+			wantErr:  "watch interval must be positive, was -1s",
+		},
+	} {
+		wd := newModule(t)
+		runner := &fakeRunner{wd: wd}
+		checker := govet.Checker{
+			VetTool: testVetTool,
+			Runner:  runner,
+			Getwd: func() (string, error) {
+				return wd, nil
+			},
+		}
+
+		buf := &bytes.Buffer{}
+		err := checker.Watch(context.Background(), []string{"./..."}, tc.interval, buf)
+		assert.EqualError(t, err, tc.wantErr, "Case %d: %s", i, tc.name)
+		assert.Empty(t, runner.cmds, "Case %d: %s", i, tc.name)
+		assert.Empty(t, buf.String(), "Case %d: %s", i, tc.name)
+	}
+}
@@ -20,7 +20,6 @@ import (
 
 	"github.com/nmiyake/pkg/gofiles"
 	"github.com/palantir/godel/v2/framework/pluginapitester"
-	"github.com/palantir/godel/v2/pkg/products"
 	"github.com/palantir/okgo/okgotester"
 	"github.com/stretchr/testify/require"
 )
@@ -39,24 +38,18 @@ func TestCheck(t *testing.T) {
     - "godel"
 `
 
-	assetPath, err := products.Bin("govet-asset")
-	require.NoError(t, err)
-
 	configFiles := map[string]string{
 		"godel/config/godel.yml":        godelYML,
 		"godel/config/check-plugin.yml": "",
 	}
 
-	pluginProvider, err := pluginapitester.NewPluginProviderFromLocator(okgoPluginLocator, okgoPluginResolver)
-	require.NoError(t, err)
-
 	prevVal := os.Getenv("GOMAXPROCS")
 	if prevVal != "" {
-		err = os.Setenv("GOMAXPROCS", prevVal)
+		err := os.Setenv("GOMAXPROCS", prevVal)
 		require.NoError(t, err)
 	}
 
-	err = os.Setenv("GOMAXPROCS", "1")
+	err := os.Setenv("GOMAXPROCS", "1")
 	require.NoError(t, err)
 
 	okgotester.RunAssetCheckTest(t,
@@ -100,8 +93,8 @@ func Bar() {
 				ConfigFiles: configFiles,
 				WantError:   true,
 				WantOutput: `Running govet...
-./foo.go:7:14: fmt.Printf format %s has arg num of wrong type int
 bar/bar.go:7:14: fmt.Printf format %s has arg num of wrong type int
+./foo.go:7:14: fmt.Printf format %s has arg num of wrong type int
 Finished govet
 Check(s) produced output: [govet]
 `,
@@ -145,8 +138,8 @@ func Bar() {
 				Wd:          "inner",
 				WantError:   true,
 				WantOutput: `Running govet...
-../foo.go:7:14: fmt.Printf format %s has arg num of wrong type int
 ../bar/bar.go:7:14: fmt.Printf format %s has arg num of wrong type int
+../foo.go:7:14: fmt.Printf format %s has arg num of wrong type int
 Finished govet
 Check(s) produced output: [govet]
 `,
@@ -156,11 +149,6 @@ Check(s) produced output: [govet]
 }
 
 func TestUpgradeConfig(t *testing.T) {
-	pluginProvider, err := pluginapitester.NewPluginProviderFromLocator(okgoPluginLocator, okgoPluginResolver)
-	require.NoError(t, err)
-
-	assetPath, err := products.Bin("govet-asset")
-	require.NoError(t, err)
 	assetProvider := pluginapitester.NewAssetProvider(assetPath)
 
 	pluginapitester.RunUpgradeConfigTest(t,
@@ -240,7 +228,31 @@ checks:
 				},
 			},
 			{
-				Name: `non-empty v0 config does not work`,
+				Name: `valid v0 config works`,
+				ConfigFiles: map[string]string{
+					"godel/config/check-plugin.yml": `
+checks:
+  govet:
+    config:
+      # comment
+      reports:
+        sarif: out/govet.sarif
+`,
+				},
+				WantOutput: ``,
+				WantFiles: map[string]string{
+					"godel/config/check-plugin.yml": `
+checks:
+  govet:
+    config:
+      # comment
+      reports:
+        sarif: out/govet.sarif
+`,
+				},
+			},
+			{
+				Name: `v0 config with unknown fields does not work`,
 				ConfigFiles: map[string]string{
 					"godel/config/check-plugin.yml": `
 checks:
@@ -252,7 +264,8 @@ checks:
 				},
 				WantError: true,
 				WantOutput: `Failed to upgrade configuration:
-	godel/config/check-plugin.yml: failed to upgrade check "govet" configuration: failed to upgrade asset configuration: govet-asset does not currently support configuration
+	godel/config/check-plugin.yml: failed to upgrade check "govet" configuration: failed to upgrade asset configuration: failed to unmarshal govet-asset v0 configuration: yaml: unmarshal errors:
+  line 1: field key not found in type v0.Config
 `,
 				WantFiles: map[string]string{
 					"godel/config/check-plugin.yml": `
@@ -0,0 +1,126 @@
+// Copyright 2016 Palantir Technologies, Inc.
+//
+// Licensed under the Apache License, Version 2.0 (the "License");
+// you may not use this file except in compliance with the License.
+// You may obtain a copy of the License at
+//
+//     http://www.apache.org/licenses/LICENSE-2.0
+//
+// Unless required by applicable law or agreed to in writing, software
+// distributed under the License is distributed on an "AS IS" BASIS,
+// WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+// See the License for the specific language governing permissions and
+// limitations under the License.
+
+package integration_test
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/palantir/godel/v2/framework/pluginapitester"
+	"github.com/pkg/errors"
+)
+
+const (
+	// okgoPluginPathEnvVar is the environment variable that specifies the path to an okgo plugin executable. If it is
+	// set, the tests use the plugin at the path.
+	okgoPluginPathEnvVar = "OKGO_PLUGIN_PATH"
+	// okgoPluginResolveEnvVar is the environment variable that, if set to "true", makes the tests resolve the okgo
+	// plugin using okgoPluginLocator and okgoPluginResolver, which requires network access.
+	okgoPluginResolveEnvVar = "OKGO_PLUGIN_RESOLVE"
+	// assetPathEnvVar is the environment variable that specifies the path to the asset executable. If it is not set,
+	// the tests build the asset from the source of this module.
+	assetPathEnvVar = "GOVET_ASSET_PATH"
+
+	okgoModule = "github.com/palantir/okgo"
+)
+
+var (
+	pluginProvider pluginapitester.PluginProvider
+	assetPath      string
+)
+
+// TestMain builds the asset and determines the okgo plugin that are used by the tests. By default, the okgo plugin is
+// built from the vendored source of the okgo module, so that the tests do not require network access.
+func TestMain(m *testing.M) {
+	os.Exit(runTests(m))
+}
+
+func runTests(m *testing.M) int {
+	tmpDir, err := os.MkdirTemp("", "govet-asset-integration-")
+	if err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	defer func() {
+		_ = os.RemoveAll(tmpDir)
+	}()
+
+	assetPath = os.Getenv(assetPathEnvVar)
+	if assetPath == "" {
+		if assetPath, err = buildAsset(tmpDir); err != nil {
+			fmt.Fprintln(os.Stderr, err)
+			return 1
+		}
+	}
+	if pluginProvider, err = okgoPlugin(tmpDir); err != nil {
+		fmt.Fprintln(os.Stderr, err)
+		return 1
+	}
+	return m.Run()
+}
+
+// buildAsset builds the asset from the source of this module into the provided directory and returns the path to the
+// executable.
+func buildAsset(dir string) (string, error) {
+	assetPath := filepath.Join(dir, "govet-asset")
+	if err := runGo("..", "build", "-o", assetPath, "."); err != nil {
+		return "", errors.Wrapf(err, "failed to build asset")
+	}
+	return assetPath, nil
+}
+
+// okgoPlugin returns the provider of the okgo plugin that is used by the tests.
+func okgoPlugin(dir string) (pluginapitester.PluginProvider, error) {
+	if pluginPath := os.Getenv(okgoPluginPathEnvVar); pluginPath != "" {
+		return pluginapitester.NewPluginProvider(pluginPath), nil
+	}
+	if os.Getenv(okgoPluginResolveEnvVar) == "true" {
+		return pluginapitester.NewPluginProviderFromLocator(okgoPluginLocator, okgoPluginResolver)
+	}
+	pluginPath, err := buildOKGoPlugin(dir)
+	if err != nil {
+		return nil, errors.Wrapf(err, "failed to build okgo plugin: set %s to the path of an okgo plugin or set %s=true to download it", okgoPluginPathEnvVar, okgoPluginResolveEnvVar)
+	}
+	return pluginapitester.NewPluginProvider(pluginPath), nil
+}
+
+// buildOKGoPlugin builds the okgo plugin from the vendored source of the okgo module, which is a tool dependency of this
+// module, into the provided directory and returns the path to the executable. The build does not access the network or
+// the module cache.
+func buildOKGoPlugin(dir string) (string, error) {
+	if _, err := os.Stat(filepath.Join("..", "vendor", filepath.FromSlash(okgoModule), "main.go")); err != nil {
+		return "", errors.Wrapf(err, "vendored source of %s not found: run \"go mod vendor\"", okgoModule)
+	}
+	pluginPath := filepath.Join(dir, "okgo-plugin")
+	if err := runGo("..", "build", "-mod=vendor", "-o", pluginPath, okgoModule); err != nil {
+		return "", err
+	}
+	return pluginPath, nil
+}
+
+func runGo(dir string, args ...string) error {
+	cmd := exec.Command("go", args...)
+	cmd.Dir = dir
+	// flags from the environment, such as "-mod=vendor", do not necessarily apply to the module that is built
+	cmd.Env = append(os.Environ(), "GOFLAGS=")
+	if output, err := cmd.CombinedOutput(); err != nil {
+		return errors.Wrapf(err, "failed to run command %v in %s: %s", cmd.Args, dir, strings.TrimSpace(string(output)))
+	}
+	return nil
+}
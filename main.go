@@ -17,6 +17,8 @@ package main
 import (
 	"os"
 
+	"github.com/palantir/godel-okgo-asset-govet/cmd"
+	"github.com/palantir/godel-okgo-asset-govet/govet"
 	"github.com/palantir/godel-okgo-asset-govet/govet/config"
 	"github.com/palantir/godel-okgo-asset-govet/govet/creator"
 	"github.com/palantir/okgo/checker"
@@ -24,6 +26,15 @@ import (
 )
 
 func main() {
-	rootCmd := checker.AssetRootCmd(creator.Govet(), config.UpgradeConfig, "run go vet check")
+	govetCreator := creator.Govet()
+	if govet.IsVetToolInvocation() {
+		govet.VetToolMain()
+	}
+	rootCmd := checker.AssetRootCmd(govetCreator, config.UpgradeConfig, "run go vet check")
+	rootCmd.AddCommand(cmd.NewListAnalyzersCmd(govetCreator))
+	rootCmd.AddCommand(cmd.NewExplainCmd())
+	rootCmd.AddCommand(cmd.NewVetFileCmd(govetCreator))
+	rootCmd.AddCommand(cmd.NewWatchCmd(govetCreator))
+	rootCmd.AddCommand(cmd.NewDoctorCmd(govetCreator))
 	os.Exit(cobracli.ExecuteWithDefaultParams(rootCmd))
 }